@@ -0,0 +1,133 @@
+package xid
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+)
+
+// IDs is a slice of IDs stored in SQL databases as a PostgreSQL array literal
+// of base32 ids, i.e. in a text[] column. Nil IDs are stored as NULL elements.
+//
+// Scan also accepts arrays of ids in the \x hex form of bytea[] columns, so
+// IDs can read both representations without depending on a specific driver.
+type IDs []ID
+
+// Value implements the driver.Valuer interface.
+func (ids IDs) Value() (driver.Value, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	b := make([]byte, 0, 2+len(ids)*(encodedLen+1))
+	b = append(b, '{')
+	for i, id := range ids {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if id.IsNil() {
+			b = append(b, "NULL"...)
+			continue
+		}
+		b, _ = id.AppendText(b)
+	}
+	return string(append(b, '}')), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (ids *IDs) Scan(value interface{}) error {
+	var b []byte
+	switch val := value.(type) {
+	case string:
+		b = []byte(val)
+	case []byte:
+		b = val
+	case nil:
+		*ids = nil
+		return nil
+	default:
+		return fmt.Errorf("xid: scanning unsupported type: %T", value)
+	}
+	elems, err := parseArray(b)
+	if err != nil {
+		return err
+	}
+	s := make(IDs, len(elems))
+	for i, e := range elems {
+		if s[i], err = decodeArrayElem(e); err != nil {
+			return err
+		}
+	}
+	*ids = s
+	return nil
+}
+
+// decodeArrayElem reads an id from an array element, either in its base32
+// form or in the \x hex form of bytea values. A nil element is a NULL.
+func decodeArrayElem(e []byte) (ID, error) {
+	if e == nil {
+		return nilID, nil
+	}
+	if bytes.HasPrefix(e, []byte(`\x`)) {
+		return FromHex(string(e[2:]))
+	}
+	var id ID
+	err := id.UnmarshalText(e)
+	return id, err
+}
+
+// parseArray splits a one-dimensional PostgreSQL array literal into its
+// unquoted elements. NULL elements are returned as nil.
+func parseArray(b []byte) ([][]byte, error) {
+	if len(b) < 2 || b[0] != '{' || b[len(b)-1] != '}' {
+		return nil, ErrInvalidArray
+	}
+	b = bytes.TrimSpace(b[1 : len(b)-1])
+	elems := [][]byte{}
+	if len(b) == 0 {
+		return elems, nil
+	}
+	for {
+		var e []byte
+		if b[0] == '"' {
+			e = []byte{}
+			i := 1
+			for ; i < len(b) && b[i] != '"'; i++ {
+				if b[i] == '\\' {
+					if i++; i == len(b) {
+						break
+					}
+				}
+				e = append(e, b[i])
+			}
+			if i >= len(b) {
+				// Unterminated quoted element
+				return nil, ErrInvalidArray
+			}
+			b = b[i+1:]
+		} else {
+			i := bytes.IndexByte(b, ',')
+			if i < 0 {
+				i = len(b)
+			}
+			e, b = bytes.TrimSpace(b[:i]), b[i:]
+			if len(e) == 0 || bytes.ContainsAny(e, `{}"\`) {
+				// Empty or nested elements are not supported
+				return nil, ErrInvalidArray
+			}
+			if bytes.EqualFold(e, []byte("NULL")) {
+				e = nil
+			}
+		}
+		elems = append(elems, e)
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			return elems, nil
+		}
+		if b[0] != ',' {
+			return nil, ErrInvalidArray
+		}
+		if b = bytes.TrimSpace(b[1:]); len(b) == 0 {
+			return nil, ErrInvalidArray
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package xid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestIDsValue(t *testing.T) {
+	id1, id2 := testIDs[0].id, testIDs[0].id
+	id2[rawLen-1]++
+	tests := []struct {
+		ids  IDs
+		want interface{}
+	}{
+		{nil, nil},
+		{IDs{}, "{}"},
+		{IDs{id1}, "{9m4e2mr0ui3e8a215n4g}"},
+		{IDs{id1, nilID, id2}, "{9m4e2mr0ui3e8a215n4g,NULL,9m4e2mr0ui3e8a215n50}"},
+	}
+	for _, tt := range tests {
+		got, err := tt.ids.Value()
+		if err != nil || got != tt.want {
+			t.Errorf("%v.Value() = %v, %v, want %v", tt.ids, got, err, tt.want)
+		}
+	}
+}
+
+func TestIDsScan(t *testing.T) {
+	id1, id2 := testIDs[0].id, testIDs[0].id
+	id2[rawLen-1]++
+	tests := []struct {
+		value interface{}
+		want  IDs
+	}{
+		{nil, nil},
+		{"{}", IDs{}},
+		{"{9m4e2mr0ui3e8a215n4g}", IDs{id1}},
+		{[]byte("{9m4e2mr0ui3e8a215n4g,9m4e2mr0ui3e8a215n50}"), IDs{id1, id2}},
+		{`{"9m4e2mr0ui3e8a215n4g", NULL ,null}`, IDs{id1, nilID, nilID}},
+		{`{"\\x4d88e15b60f486e428412dc9","\\x4d88e15b60f486e428412dca"}`, IDs{id1, id2}},
+		{`{"\\x4d88e15b60f486e428412dc9",NULL,9m4e2mr0ui3e8a215n50}`, IDs{id1, nilID, id2}},
+	}
+	for _, tt := range tests {
+		var got IDs
+		if err := got.Scan(tt.value); err != nil {
+			t.Errorf("Scan(%q) err=%v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Scan(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIDsScanInvalid(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  error
+	}{
+		{"", ErrInvalidArray},
+		{"9m4e2mr0ui3e8a215n4g", ErrInvalidArray},
+		{"{9m4e2mr0ui3e8a215n4g", ErrInvalidArray},
+		{"{9m4e2mr0ui3e8a215n4g,}", ErrInvalidArray},
+		{"{,9m4e2mr0ui3e8a215n4g}", ErrInvalidArray},
+		{`{"9m4e2mr0ui3e8a215n4g}`, ErrInvalidArray},
+		{`{"9m4e2mr0ui3e8a215n4g" 9m4e2mr0ui3e8a215n50}`, ErrInvalidArray},
+		{"{{9m4e2mr0ui3e8a215n4g}}", ErrInvalidArray},
+		{"{9m4e2mr0ui3e8a215n4}", ErrInvalidID},
+		{`{"\\x4d88e15b60f486e428412dc"}`, ErrInvalidID},
+		{`{""}`, ErrInvalidID},
+	}
+	for _, tt := range tests {
+		var got IDs
+		if err := got.Scan(tt.value); !errors.Is(err, tt.want) {
+			t.Errorf("Scan(%q) err=%v, want %v", tt.value, err, tt.want)
+		}
+	}
+	var got IDs
+	if err := got.Scan(1); err == nil {
+		t.Error("Scan(1) err=nil, want an error")
+	}
+}
+
+func TestIDsRoundTrip(t *testing.T) {
+	ids := IDs{testIDs[0].id, nilID, testIDs[2].id}
+	v, _ := ids.Value()
+	var got IDs
+	if err := got.Scan(v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ids) {
+		t.Errorf("Scan(%v) = %v, want %v", v, got, ids)
+	}
+}
@@ -2,11 +2,21 @@ package xidb
 
 import (
 	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/rs/xid"
 )
 
+const (
+	rawLen = 12 // binary raw len
+	hexLen = 24 // hex encoded len
+)
+
+// ID is an xid.ID stored in SQL databases as 12 raw bytes, i.e. in a
+// BINARY(12) or BYTEA column. Scan also accepts the string and hex forms
+// stored by StringID and HexID, so columns can be migrated from one form to
+// another.
 type ID struct {
 	xid.ID
 }
@@ -21,18 +31,159 @@ func (id ID) Value() (driver.Value, error) {
 
 // Scan implements the sql.Scanner interface.
 func (id *ID) Scan(value interface{}) (err error) {
+	i, err := scan(value)
+	if err != nil {
+		return err
+	}
+	*id = ID{ID: i}
+	return nil
+}
+
+// StringID is an xid.ID stored in SQL databases as its 20 chars base32
+// representation, i.e. in a CHAR(20) column. Scan also accepts the raw bytes
+// and hex forms.
+type StringID struct {
+	xid.ID
+}
+
+// Value implements the driver.Valuer interface.
+func (id StringID) Value() (driver.Value, error) {
+	if id.ID.IsNil() {
+		return nil, nil
+	}
+	return id.ID.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (id *StringID) Scan(value interface{}) (err error) {
+	i, err := scan(value)
+	if err != nil {
+		return err
+	}
+	*id = StringID{ID: i}
+	return nil
+}
+
+// HexID is an xid.ID stored in SQL databases as its 24 chars hex
+// representation, i.e. in a CHAR(24) column. Scan also accepts the raw bytes
+// and string forms.
+type HexID struct {
+	xid.ID
+}
+
+// Value implements the driver.Valuer interface.
+func (id HexID) Value() (driver.Value, error) {
+	if id.ID.IsNil() {
+		return nil, nil
+	}
+	return id.ID.Hex(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (id *HexID) Scan(value interface{}) (err error) {
+	i, err := scan(value)
+	if err != nil {
+		return err
+	}
+	*id = HexID{ID: i}
+	return nil
+}
+
+// scan reads an id stored as 12 raw bytes or as its base32 or hex
+// representation, as a string or bytes. The form is detected from the length
+// of the value.
+func scan(value interface{}) (xid.ID, error) {
+	var b []byte
 	switch val := value.(type) {
 	case []byte:
-		i, err := xid.FromBytes(val)
-		if err != nil {
-			return err
-		}
-		*id = ID{ID: i}
-		return nil
+		b = val
+	case string:
+		b = []byte(val)
 	case nil:
-		*id = ID{ID: xid.NilID()}
-		return nil
+		return xid.NilID(), nil
+	default:
+		return xid.NilID(), fmt.Errorf("xid: scanning unsupported type: %T", value)
+	}
+	switch len(b) {
+	case rawLen:
+		return xid.FromBytes(b)
+	case hexLen:
+		return xid.FromHex(string(b))
 	default:
-		return fmt.Errorf("xid: scanning unsupported type: %T", value)
+		return xid.FromString(string(b))
+	}
+}
+
+// NullID represents an ID that may be null. Unlike ID, which maps the nil ID
+// to SQL NULL, it distinguishes a NULL value from a stored nil ID, like
+// sql.NullString does for strings.
+type NullID struct {
+	ID    ID
+	Valid bool // Valid is true if ID is not NULL
+}
+
+// Value implements the driver.Valuer interface. A valid nil ID is stored as
+// 12 zero bytes, not as NULL.
+func (n NullID) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.ID.ID[:], nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullID) Scan(value interface{}) error {
+	if value == nil {
+		*n = NullID{}
+		return nil
 	}
+	err := n.ID.Scan(value)
+	n.Valid = err == nil
+	return err
+}
+
+// MarshalJSON implements encoding/json Marshaler interface
+func (n NullID) MarshalJSON() ([]byte, error) {
+	return xid.NullID{ID: n.ID.ID, Valid: n.Valid}.MarshalJSON()
+}
+
+// UnmarshalJSON implements encoding/json Unmarshaler interface
+func (n *NullID) UnmarshalJSON(b []byte) error {
+	var v xid.NullID
+	err := v.UnmarshalJSON(b)
+	*n = NullID{ID: ID{ID: v.ID}, Valid: v.Valid}
+	return err
+}
+
+// IDs is a slice of IDs stored in SQL databases as a PostgreSQL array literal
+// of ids in the \x hex form of bytea values, i.e. in a bytea[] column. Nil IDs
+// are stored as NULL elements. Scan also accepts text[] arrays of base32 ids.
+type IDs []xid.ID
+
+// Value implements the driver.Valuer interface.
+func (ids IDs) Value() (driver.Value, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	b := make([]byte, 0, 2+len(ids)*(hexLen+6))
+	b = append(b, '{')
+	for i, id := range ids {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if id.IsNil() {
+			b = append(b, "NULL"...)
+			continue
+		}
+		b = append(b, `"\\x`...)
+		b = append(b, make([]byte, hexLen)...)
+		hex.Encode(b[len(b)-hexLen:], id[:])
+		b = append(b, '"')
+	}
+	return string(append(b, '}')), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (ids *IDs) Scan(value interface{}) error {
+	return (*xid.IDs)(ids).Scan(value)
 }
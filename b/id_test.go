@@ -1,6 +1,8 @@
 package xidb
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"reflect"
 	"testing"
 
@@ -56,11 +58,41 @@ func TestIDScan(t *testing.T) {
 			val:        nil,
 			expectedID: ID{ID: xid.NilID()},
 		},
+		{
+			name:       "string id",
+			val:        "9m4e2mr0ui3e8a215n4g",
+			expectedID: ID{ID: i},
+		},
+		{
+			name:       "string id as bytes",
+			val:        []byte("9m4e2mr0ui3e8a215n4g"),
+			expectedID: ID{ID: i},
+		},
+		{
+			name:       "hex id",
+			val:        "4d88e15b60f486e428412dc9",
+			expectedID: ID{ID: i},
+		},
+		{
+			name:       "hex id as bytes",
+			val:        []byte("4d88e15b60f486e428412dc9"),
+			expectedID: ID{ID: i},
+		},
 		{
 			name:        "wrong bytes",
 			val:         []byte{0x01},
 			expectedErr: true,
 		},
+		{
+			name:        "invalid string",
+			val:         "9m4e2mr0ui3e8a215n4z",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid hex",
+			val:         "4d88e15b60f486e428412dcz",
+			expectedErr: true,
+		},
 		{
 			name:        "unknown type",
 			val:         1,
@@ -84,3 +116,227 @@ func TestIDScan(t *testing.T) {
 		})
 	}
 }
+
+func TestStringIDValue(t *testing.T) {
+	i, _ := xid.FromString("9m4e2mr0ui3e8a215n4g")
+
+	tests := []struct {
+		name        string
+		id          interface{ Value() (driver.Value, error) }
+		expectedVal interface{}
+	}{
+		{
+			name:        "string id",
+			id:          StringID{ID: i},
+			expectedVal: "9m4e2mr0ui3e8a215n4g",
+		},
+		{
+			name:        "nil string id",
+			id:          StringID{ID: xid.NilID()},
+			expectedVal: nil,
+		},
+		{
+			name:        "hex id",
+			id:          HexID{ID: i},
+			expectedVal: "4d88e15b60f486e428412dc9",
+		},
+		{
+			name:        "nil hex id",
+			id:          HexID{ID: xid.NilID()},
+			expectedVal: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.id.Value()
+			if !reflect.DeepEqual(got, tt.expectedVal) {
+				t.Errorf("wanted %v, got %v", tt.expectedVal, got)
+			}
+		})
+	}
+}
+
+func TestStringIDScan(t *testing.T) {
+	i, _ := xid.FromString("9m4e2mr0ui3e8a215n4g")
+
+	for _, val := range []interface{}{i.Bytes(), i.String(), i.Hex()} {
+		var s StringID
+		if err := s.Scan(val); err != nil || s.ID != i {
+			t.Errorf("StringID.Scan(%v) = %v, %v, wanted %v", val, s.ID, err, i)
+		}
+		var h HexID
+		if err := h.Scan(val); err != nil || h.ID != i {
+			t.Errorf("HexID.Scan(%v) = %v, %v, wanted %v", val, h.ID, err, i)
+		}
+	}
+}
+
+func TestNullIDValue(t *testing.T) {
+	i, _ := xid.FromString("9m4e2mr0ui3e8a215n4g")
+
+	tests := []struct {
+		name        string
+		id          NullID
+		expectedVal interface{}
+	}{
+		{
+			name:        "valid id",
+			id:          NullID{ID: ID{ID: i}, Valid: true},
+			expectedVal: i.Bytes(),
+		},
+		{
+			name:        "valid nil id",
+			id:          NullID{ID: ID{ID: xid.NilID()}, Valid: true},
+			expectedVal: make([]byte, 12),
+		},
+		{
+			name:        "null",
+			id:          NullID{ID: ID{ID: i}},
+			expectedVal: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.id.Value()
+			if !reflect.DeepEqual(got, tt.expectedVal) {
+				t.Errorf("wanted %v, got %v", tt.expectedVal, got)
+			}
+		})
+	}
+}
+
+func TestNullIDScan(t *testing.T) {
+	i, _ := xid.FromString("9m4e2mr0ui3e8a215n4g")
+
+	tests := []struct {
+		name        string
+		val         interface{}
+		expectedID  NullID
+		expectedErr bool
+	}{
+		{
+			name:       "bytes id",
+			val:        i.Bytes(),
+			expectedID: NullID{ID: ID{ID: i}, Valid: true},
+		},
+		{
+			name:       "nil id",
+			val:        make([]byte, 12),
+			expectedID: NullID{ID: ID{ID: xid.NilID()}, Valid: true},
+		},
+		{
+			name:       "null",
+			val:        nil,
+			expectedID: NullID{},
+		},
+		{
+			name:        "wrong bytes",
+			val:         []byte{0x01},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := &NullID{}
+			err := id.Scan(tt.val)
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("error expected: %t, got %t", tt.expectedErr, (err != nil))
+			}
+			if err == nil {
+				if !reflect.DeepEqual(*id, tt.expectedID) {
+					t.Errorf("wanted %v, got %v", tt.expectedID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestNullIDJSON(t *testing.T) {
+	i, _ := xid.FromString("9m4e2mr0ui3e8a215n4g")
+
+	tests := []struct {
+		name string
+		id   NullID
+		json string
+	}{
+		{
+			name: "valid id",
+			id:   NullID{ID: ID{ID: i}, Valid: true},
+			json: `"9m4e2mr0ui3e8a215n4g"`,
+		},
+		{
+			name: "valid nil id",
+			id:   NullID{Valid: true},
+			json: `"00000000000000000000"`,
+		},
+		{
+			name: "null",
+			id:   NullID{},
+			json: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.json {
+				t.Errorf("wanted %s, got %s", tt.json, b)
+			}
+			var got NullID
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.id) {
+				t.Errorf("wanted %v, got %v", tt.id, got)
+			}
+		})
+	}
+}
+
+func TestIDsValue(t *testing.T) {
+	i, _ := xid.FromString("9m4e2mr0ui3e8a215n4g")
+
+	tests := []struct {
+		name        string
+		ids         IDs
+		expectedVal interface{}
+	}{
+		{
+			name:        "ids",
+			ids:         IDs{i, xid.NilID()},
+			expectedVal: `{"\\x4d88e15b60f486e428412dc9",NULL}`,
+		},
+		{
+			name:        "empty",
+			ids:         IDs{},
+			expectedVal: `{}`,
+		},
+		{
+			name:        "null",
+			ids:         nil,
+			expectedVal: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.ids.Value()
+			if !reflect.DeepEqual(got, tt.expectedVal) {
+				t.Errorf("wanted %v, got %v", tt.expectedVal, got)
+			}
+			var ids IDs
+			if err := ids.Scan(got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("wanted %v, got %v", tt.ids, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package xid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Unix(1300816219, 0)
+	c := NewManualClock(start)
+	if got, want := c.Now(), start; got != want {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+	if got, want := c.Advance(2*time.Second), start.Add(2*time.Second); got != want {
+		t.Errorf("Advance() = %v, want %v", got, want)
+	}
+	if got, want := c.Now(), start.Add(2*time.Second); got != want {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+	c.Set(start)
+	if got, want := c.Now(), start; got != want {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorWithClock(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	g := NewGenerator(WithClock(c))
+	if got, want := g.New().Time(), c.Now(); got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+	c.Advance(time.Hour)
+	if got, want := g.New().Time(), c.Now(); got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestSetDefaultGenerator(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	prev := SetDefaultGenerator(NewGenerator(WithClock(c)))
+	defer SetDefaultGenerator(prev)
+	if got, want := New().Time(), c.Now(); got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorRollbackIgnore(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	g := NewGenerator(WithClock(c))
+	g.New()
+	c.Advance(-time.Minute)
+	if got, want := g.New().Time(), c.Now(); got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorRollbackReuse(t *testing.T) {
+	start := time.Unix(1300816219, 0)
+	c := NewManualClock(start)
+	g := NewGenerator(WithClock(c), WithRollbackPolicy(RollbackReuse))
+	g.New()
+	c.Advance(-time.Minute)
+	if got, want := g.New().Time(), start; got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+	c.Set(start.Add(time.Second))
+	if got, want := g.New().Time(), start.Add(time.Second); got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorRollbackError(t *testing.T) {
+	start := time.Unix(1300816219, 0)
+	c := NewManualClock(start)
+	g := NewGenerator(WithClock(c), WithRollbackPolicy(RollbackError))
+	if _, err := g.NewChecked(); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(-time.Second)
+	if _, err := g.NewChecked(); err != ErrClockRollback {
+		t.Errorf("NewChecked() err=%v, want %v", err, ErrClockRollback)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrClockRollback {
+				t.Errorf("New() panic=%v, want %v", r, ErrClockRollback)
+			}
+		}()
+		g.New()
+	}()
+	c.Set(start)
+	if _, err := g.NewChecked(); err != nil {
+		t.Errorf("NewChecked() err=%v, want nil", err)
+	}
+}
+
+func TestGeneratorRollbackWait(t *testing.T) {
+	start := time.Unix(1300816219, 0)
+	c := NewManualClock(start)
+	g := NewGenerator(WithClock(c), WithRollbackPolicy(RollbackWait))
+	g.New()
+	c.Advance(-time.Minute)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		c.Set(start)
+	}()
+	if got, want := g.New().Time(), start; got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
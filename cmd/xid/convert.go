@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rs/xid"
+)
+
+func convert(e *env, args []string) int {
+	fs := newFlagSet(e, "convert")
+	format := fs.String("format", "csv", "stream format: csv or ndjson")
+	field := fs.String("field", "", "name of the CSV column or of the JSON field holding the ids, nested JSON fields being separated by dots")
+	from := fs.String("from", "any", "input encoding: any, raw, "+encodingNames())
+	to := fs.String("to", "base32hex", "output encoding: raw, "+encodingNames())
+	drop := fs.Bool("drop", false, "drop the invalid rows instead of copying them unchanged")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *field == "" || fs.NArg() > 0 {
+		fmt.Fprintln(e.stderr, "xid convert: the -field flag is required and the stream is read from stdin")
+		fs.Usage()
+		return 2
+	}
+	c := &converter{e: e, field: *field, drop: *drop}
+	var err error
+	if *from == "raw" {
+		c.decode = rawEncoding{}.Decode
+	} else if c.decode, err = parser(*from); err != nil {
+		fmt.Fprintf(e.stderr, "xid convert: %v\n", err)
+		return 2
+	}
+	if *to == "raw" {
+		c.enc = rawEncoding{}
+	} else if c.enc, err = lookupEncoding(*to); err != nil {
+		fmt.Fprintf(e.stderr, "xid convert: %v\n", err)
+		return 2
+	}
+	switch *format {
+	case "csv":
+		err = c.convertCSV()
+	case "ndjson":
+		if *from == "raw" || *to == "raw" {
+			fmt.Fprintln(e.stderr, "xid convert: the raw encoding is not supported with ndjson")
+			return 2
+		}
+		err = c.convertNDJSON()
+	default:
+		fmt.Fprintf(e.stderr, "xid convert: unknown format %q\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid convert: %v\n", err)
+		return 1
+	}
+	if c.bad > 0 {
+		fmt.Fprintf(e.stderr, "xid convert: %d invalid rows\n", c.bad)
+		return 1
+	}
+	return 0
+}
+
+// converter rewrites the ids of a stream from an encoding to another.
+type converter struct {
+	e      *env
+	field  string
+	decode func(string) (xid.ID, error)
+	enc    xid.Encoding
+	drop   bool
+	bad    int // number of invalid rows
+}
+
+// report reports an invalid row starting on line.
+func (c *converter) report(line int, err error) {
+	c.bad++
+	fmt.Fprintf(c.e.stderr, "xid convert: line %d: %v\n", line, err)
+}
+
+// value converts the id v read on line. Empty values are kept as is, as they
+// usually stand for NULL. It returns false and reports the row if v is not a
+// valid id.
+func (c *converter) value(line int, v string) (string, bool) {
+	if v == "" {
+		return v, true
+	}
+	id, err := c.decode(v)
+	if err != nil {
+		c.report(line, err)
+		return v, false
+	}
+	return c.enc.Encode(id), true
+}
+
+// convertCSV converts the ids of the CSV stream from stdin. The first record
+// is the header naming the columns.
+func (c *converter) convertCSV() error {
+	w := csv.NewWriter(c.e.stdout)
+	col := -1
+	err := readCSV(c.e.stdin, func(line int, rec []string, raw []byte, err error) error {
+		switch {
+		case err != nil:
+			c.report(line, err)
+			if c.drop {
+				return nil
+			}
+			// Copy the record as read, csv.Writer having buffered the
+			// previous ones.
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return err
+			}
+			if !bytes.HasSuffix(raw, []byte{'\n'}) {
+				raw = append(raw, '\n')
+			}
+			_, err := c.e.stdout.Write(raw)
+			return err
+		case col < 0:
+			for i, name := range rec {
+				if name == c.field {
+					col = i
+					break
+				}
+			}
+			if col < 0 {
+				return fmt.Errorf("no %q column in the header", c.field)
+			}
+		case col >= len(rec):
+			c.report(line, fmt.Errorf("missing %q column", c.field))
+			if c.drop {
+				return nil
+			}
+		default:
+			var ok bool
+			if rec[col], ok = c.value(line, rec[col]); !ok && c.drop {
+				return nil
+			}
+		}
+		return w.Write(rec)
+	})
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	return err
+}
+
+// readCSV calls fn with each record of the CSV stream r, the line it starts
+// on and its raw bytes, only valid until fn returns. Unlike csv.Reader, it
+// keeps track of the line of the records having quoted line breaks, keeps
+// the "\r\n" of quoted fields, which raw ids may hold, instead of turning them
+// into "\n", and calls fn with the error of malformed records before reading
+// the next ones. It stops at the first error returned by fn.
+func readCSV(r io.Reader, fn func(line int, rec []string, raw []byte, err error) error) error {
+	br := bufio.NewReader(r)
+	var record []byte
+	start := 0
+	inQuotes := false
+	for line := 1; ; line++ {
+		l, err := br.ReadBytes('\n')
+		if len(record) == 0 {
+			start = line
+		}
+		record = append(record, l...)
+		// A record only goes on past a line break within a quoted field.
+		inQuotes = quoted(l, inQuotes)
+		if len(record) > 0 && (!inQuotes || err == io.EOF) {
+			rec, perr := splitCSV(record)
+			if perr != io.EOF {
+				if ferr := fn(start, rec, record, perr); ferr != nil {
+					return ferr
+				}
+			}
+			record = record[:0]
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// quoted returns whether the CSV line l ends within a quoted field, l starting
+// within one if inQuotes is set. Only the fields starting with a quote are
+// quoted: a quote within another field is a bare quote, which doesn't escape
+// line breaks and is reported by splitCSV.
+func quoted(l []byte, inQuotes bool) bool {
+	fieldStart := !inQuotes
+	for i := 0; i < len(l); i++ {
+		switch {
+		case inQuotes:
+			if l[i] == '"' {
+				if i+1 < len(l) && l[i+1] == '"' {
+					i++ // escaped quote
+				} else {
+					inQuotes = false
+				}
+			}
+		case l[i] == '"' && fieldStart:
+			inQuotes = true
+		}
+		fieldStart = !inQuotes && l[i] == ','
+	}
+	return inQuotes
+}
+
+// convertNDJSON converts the ids of the NDJSON stream from stdin. Rows are
+// re-encoded with their keys sorted.
+func (c *converter) convertNDJSON() error {
+	path := strings.Split(c.field, ".")
+	br := bufio.NewReader(c.e.stdin)
+	w := bufio.NewWriter(c.e.stdout)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for line := 1; ; line++ {
+		l, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(l)) > 0 {
+			if ok := c.convertJSONRow(enc, path, line, l); !ok && !c.drop {
+				w.Write(bytes.TrimRight(l, "\r\n"))
+				w.WriteByte('\n')
+			}
+		}
+		if err == io.EOF {
+			return w.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// convertJSONRow converts the id at path in the JSON object l and writes the
+// object to enc. It returns false and reports the row if it's invalid.
+func (c *converter) convertJSONRow(enc *json.Encoder, path []string, line int, l []byte) bool {
+	var row map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(l))
+	d.UseNumber()
+	if err := d.Decode(&row); err != nil {
+		c.report(line, fmt.Errorf("invalid JSON object: %v", err))
+		return false
+	}
+	obj := row
+	for _, name := range path[:len(path)-1] {
+		if obj, _ = obj[name].(map[string]interface{}); obj == nil {
+			c.report(line, fmt.Errorf("missing %q field", c.field))
+			return false
+		}
+	}
+	key := path[len(path)-1]
+	switch v := obj[key].(type) {
+	case string:
+		s, ok := c.value(line, v)
+		if !ok {
+			return false
+		}
+		obj[key] = s
+	case nil:
+		if _, found := obj[key]; !found {
+			c.report(line, fmt.Errorf("missing %q field", c.field))
+			return false
+		}
+	default:
+		c.report(line, fmt.Errorf("%q field is not a string", c.field))
+		return false
+	}
+	if err := enc.Encode(row); err != nil {
+		c.report(line, err)
+		return false
+	}
+	return true
+}
+
+// splitCSV returns the fields of the CSV record, or io.EOF if it's an empty
+// line. It reports malformed records with the errors of csv.Reader.
+func splitCSV(record []byte) ([]string, error) {
+	record = bytes.TrimSuffix(record, []byte{'\n'})
+	record = bytes.TrimSuffix(record, []byte{'\r'})
+	if len(record) == 0 {
+		return nil, io.EOF
+	}
+	var fields []string
+	for {
+		var field []byte
+		if record[0] == '"' {
+			record = record[1:]
+			for {
+				i := bytes.IndexByte(record, '"')
+				if i < 0 {
+					return nil, csv.ErrQuote
+				}
+				field = append(field, record[:i]...)
+				record = record[i+1:]
+				if len(record) == 0 || record[0] != '"' {
+					break
+				}
+				field = append(field, '"') // escaped quote
+				record = record[1:]
+			}
+			if len(record) > 0 && record[0] != ',' {
+				return nil, csv.ErrQuote
+			}
+		} else {
+			i := bytes.IndexByte(record, ',')
+			if i < 0 {
+				i = len(record)
+			}
+			if field = record[:i]; bytes.IndexByte(field, '"') >= 0 {
+				return nil, csv.ErrBareQuote
+			}
+			record = record[i:]
+		}
+		fields = append(fields, string(field))
+		if len(record) == 0 {
+			return fields, nil
+		}
+		record = record[1:] // comma
+		if len(record) == 0 {
+			return append(fields, ""), nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/rs/xid"
+)
+
+// base64Encoding is the 16 chars standard base64 encoding (RFC 4648). As ids
+// are 12 bytes long, it never needs padding.
+type base64Encoding struct{}
+
+func (base64Encoding) Name() string { return "base64" }
+
+func (base64Encoding) Encode(id xid.ID) string {
+	return base64.StdEncoding.EncodeToString(id[:])
+}
+
+func (base64Encoding) Decode(s string) (xid.ID, error) {
+	b, err := base64.StdEncoding.Strict().DecodeString(s)
+	if err != nil {
+		return xid.NilID(), xid.ErrInvalidID
+	}
+	return xid.FromBytes(b)
+}
+
+func (base64Encoding) Sortable() bool { return false }
+
+// byteaEncoding is the \x hex form of PostgreSQL bytea values, as found in
+// exports of binary columns.
+type byteaEncoding struct{}
+
+func (byteaEncoding) Name() string { return "bytea" }
+
+func (byteaEncoding) Encode(id xid.ID) string {
+	return `\x` + hex.EncodeToString(id[:])
+}
+
+func (byteaEncoding) Decode(s string) (xid.ID, error) {
+	if !strings.HasPrefix(s, `\x`) {
+		return xid.NilID(), xid.ErrInvalidID
+	}
+	return xid.FromHex(s[2:])
+}
+
+func (byteaEncoding) Sortable() bool { return true }
+
+// rawEncoding writes ids as their 12 raw bytes. It's only supported in CSV
+// streams, as JSON strings can't hold arbitrary bytes.
+type rawEncoding struct{}
+
+func (rawEncoding) Name() string { return "raw" }
+
+func (rawEncoding) Encode(id xid.ID) string { return string(id[:]) }
+
+func (rawEncoding) Decode(s string) (xid.ID, error) { return xid.FromBytes([]byte(s)) }
+
+func (rawEncoding) Sortable() bool { return true }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+func gen(e *env, args []string) int {
+	fs := newFlagSet(e, "gen")
+	n := fs.Int("n", 1, "number of ids to generate")
+	at := fs.String("time", "", "time of the ids, as RFC 3339 or Unix seconds (default now)")
+	encName := fs.String("encoding", "base32hex", "output encoding: "+encodingNames())
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	enc, err := lookupEncoding(*encName)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid gen: %v\n", err)
+		return 2
+	}
+	newID := xid.DefaultGenerator().NewChecked
+	if *at != "" {
+		t, err := parseTime(*at)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "xid gen: %v\n", err)
+			return 2
+		}
+		newID = func() (xid.ID, error) { return xid.NewWithTimeChecked(t) }
+	}
+	w := bufio.NewWriter(e.stdout)
+	defer w.Flush()
+	for i := 0; i < *n; i++ {
+		id, err := newID()
+		if err != nil {
+			fmt.Fprintf(e.stderr, "xid gen: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(w, enc.Encode(id))
+	}
+	return 0
+}
+
+// parseTime reads a time in the RFC 3339 format or as Unix seconds.
+func parseTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("invalid time %q: expected RFC 3339 or Unix seconds", s)
+	}
+	return t, nil
+}
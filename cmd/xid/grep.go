@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/rs/xid"
+)
+
+// rangeFlags registers the -since and -until flags on fs. The returned
+// function gives the options filtering out the ids outside of the time range.
+func rangeFlags(fs *flag.FlagSet) func() ([]xid.FindOption, error) {
+	since := fs.String("since", "", "only report ids generated from this time, as RFC 3339 or Unix seconds")
+	until := fs.String("until", "", "only report ids generated until this time, as RFC 3339 or Unix seconds")
+	return func() ([]xid.FindOption, error) {
+		if *since == "" && *until == "" {
+			return nil, nil
+		}
+		start, end := xid.MinTime, xid.MaxTime
+		var err error
+		if *since != "" {
+			if start, err = parseTime(*since); err != nil {
+				return nil, err
+			}
+		}
+		if *until != "" {
+			if end, err = parseTime(*until); err != nil {
+				return nil, err
+			}
+		}
+		return []xid.FindOption{xid.FindInRange(xid.NewIDRange(start, end))}, nil
+	}
+}
+
+// readLines calls fn with each line of r, including its line break, and its
+// number, starting at 1.
+func readLines(r io.Reader, fn func(n int, line []byte)) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			fn(n, line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func grep(e *env, args []string) int {
+	fs := newFlagSet(e, "grep")
+	only := fs.Bool("o", false, "print the ids found instead of the matching lines, one per line")
+	number := fs.Bool("n", false, "prefix each output line with its line number")
+	findOpts := rangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	opts, err := findOpts()
+	if err != nil || fs.NArg() > 0 {
+		if err == nil {
+			err = fmt.Errorf("unexpected argument %q: the text is read from stdin", fs.Arg(0))
+		}
+		fmt.Fprintf(e.stderr, "xid grep: %v\n", err)
+		return 2
+	}
+	w := bufio.NewWriter(e.stdout)
+	found := false
+	err = readLines(e.stdin, func(n int, line []byte) {
+		matches := xid.FindAll(line, opts...)
+		if len(matches) == 0 {
+			return
+		}
+		found = true
+		prefix := ""
+		if *number {
+			prefix = strconv.Itoa(n) + ":"
+		}
+		if !*only {
+			w.WriteString(prefix)
+			w.Write(line)
+			if line[len(line)-1] != '\n' {
+				w.WriteByte('\n')
+			}
+			return
+		}
+		for _, m := range matches {
+			w.WriteString(prefix)
+			w.Write(line[m.Start:m.End])
+			w.WriteByte('\n')
+		}
+	})
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid grep: %v\n", err)
+		return 2
+	}
+	if !found {
+		return 1
+	}
+	return 0
+}
+
+func redact(e *env, args []string) int {
+	fs := newFlagSet(e, "redact")
+	mode := fs.String("mode", "replace", "redaction mode: replace or hash")
+	replacement := fs.String("replacement", "[xid]", "text replacing the ids in replace mode")
+	keyFile := fs.String("key-file", "", "file holding the secret HMAC key, required in hash mode")
+	findOpts := rangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	opts, err := findOpts()
+	if err != nil || fs.NArg() > 0 {
+		if err == nil {
+			err = fmt.Errorf("unexpected argument %q: the text is read from stdin", fs.Arg(0))
+		}
+		fmt.Fprintf(e.stderr, "xid redact: %v\n", err)
+		return 2
+	}
+	var repl func(id xid.ID) []byte
+	switch *mode {
+	case "replace":
+		r := []byte(*replacement)
+		repl = func(xid.ID) []byte { return r }
+	case "hash":
+		if *keyFile == "" {
+			fmt.Fprintln(e.stderr, "xid redact: the -key-file flag is required in hash mode")
+			fs.Usage()
+			return 2
+		}
+		key, err := os.ReadFile(*keyFile)
+		if err == nil && len(key) == 0 {
+			err = fmt.Errorf("empty key file %s", *keyFile)
+		}
+		if err != nil {
+			fmt.Fprintf(e.stderr, "xid redact: %v\n", err)
+			return 2
+		}
+		repl = hasher(key)
+	default:
+		fmt.Fprintf(e.stderr, "xid redact: unknown mode %q\n", *mode)
+		return 2
+	}
+	w := bufio.NewWriter(e.stdout)
+	err = readLines(e.stdin, func(n int, line []byte) {
+		w.Write(xid.ReplaceAll(line, repl, opts...))
+	})
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid redact: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// hasher returns a replacement function writing "h:" followed by the first
+// 16 hex chars of the HMAC-SHA256 of the id with key. The same id always gets
+// the same hash, so redacted logs can still be correlated. As ids have a
+// predictable structure, a plain hash could be reversed by brute force: key
+// must be kept secret.
+func hasher(key []byte) func(id xid.ID) []byte {
+	return func(id xid.ID) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(id[:])
+		sum := mac.Sum(nil)
+		out := make([]byte, 2+16)
+		copy(out, "h:")
+		hex.Encode(out[2:], sum[:8])
+		return out
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// components is the JSON representation of the components of an id.
+type components struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Machine string    `json:"machine"`
+	Pid     uint16    `json:"pid"`
+	Counter int32     `json:"counter"`
+	Hosts   []string  `json:"hosts,omitempty"`
+}
+
+func newComponents(id xid.ID) components {
+	return components{
+		ID:      id.String(),
+		Time:    id.Time().UTC(),
+		Machine: hex.EncodeToString(id.Machine()),
+		Pid:     id.Pid(),
+		Counter: id.Counter(),
+	}
+}
+
+func inspect(e *env, args []string) int {
+	fs := newFlagSet(e, "inspect")
+	format := fs.String("format", "table", "output format: table or json")
+	encName := fs.String("encoding", "any", "input encoding: any, "+encodingNames())
+	registry := fs.String("registry", "", "file mapping host labels to their machine id or hostname, to print the candidate hosts of the ids")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	parse, err := parser(*encName)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid inspect: %v\n", err)
+		return 2
+	}
+	var reg *xid.Registry
+	if *registry != "" {
+		if reg, err = xid.LoadRegistry(*registry); err != nil {
+			fmt.Fprintf(e.stderr, "xid inspect: %v\n", err)
+			return 2
+		}
+	}
+	var output func(c components)
+	switch *format {
+	case "table":
+		w := tabwriter.NewWriter(e.stdout, 0, 8, 2, ' ', 0)
+		defer w.Flush()
+		if reg == nil {
+			fmt.Fprintln(w, "ID\tTIME\tMACHINE\tPID\tCOUNTER")
+		} else {
+			fmt.Fprintln(w, "ID\tTIME\tMACHINE\tPID\tCOUNTER\tHOSTS")
+		}
+		output = func(c components) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d", c.ID, c.Time.Format(time.RFC3339), c.Machine, c.Pid, c.Counter)
+			if reg != nil {
+				hosts := strings.Join(c.Hosts, ",")
+				if hosts == "" {
+					hosts = "-"
+				}
+				fmt.Fprintf(w, "\t%s", hosts)
+			}
+			fmt.Fprintln(w)
+		}
+	case "json":
+		enc := json.NewEncoder(e.stdout)
+		output = func(c components) {
+			_ = enc.Encode(c)
+		}
+	default:
+		fmt.Fprintf(e.stderr, "xid inspect: unknown format %q\n", *format)
+		return 2
+	}
+	status := 0
+	err = readInputs(e, fs.Args(), func(n int, s string) {
+		id, err := parse(s)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "xid inspect: %d: %v\n", n, err)
+			status = 1
+			return
+		}
+		c := newComponents(id)
+		if reg != nil {
+			c.Hosts = reg.Lookup(id.Machine())
+		}
+		output(c)
+	})
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid inspect: %v\n", err)
+		return 1
+	}
+	return status
+}
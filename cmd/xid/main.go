@@ -0,0 +1,155 @@
+// Command xid generates, inspects, validates, converts, finds and analyzes
+// xids.
+//
+// Usage:
+//
+//	xid gen [-n count] [-time time] [-encoding name]
+//	xid inspect [-format table|json] [-encoding name] [-registry file] [id ...]
+//	xid validate [-q] [-encoding name] [id ...]
+//	xid convert -field name [-format csv|ndjson] [-from name] [-to name] [-drop]
+//	xid grep [-o] [-n] [-since time] [-until time]
+//	xid redact [-mode replace|hash] [-replacement text] [-key-file file] [-since time] [-until time]
+//	xid stats [-format table|json] [-encoding name] [-max-rate rate] [id ...]
+//
+// The inspect, validate and stats commands read ids from the standard input,
+// one per line, when none is given as argument. The convert command rewrites
+// the ids of a column of a CSV stream, or of a field of a NDJSON stream, from
+// the standard input to another encoding. The grep command prints the lines
+// of the standard input holding ids, and exits with the status 1 if none is
+// found. The redact command replaces or hashes the ids of the standard input.
+// The stats command groups ids by machine id and pid to report the time
+// range, generation rate and restarts of the processes, and the duplicated
+// ids.
+//
+// The exit status is 1 when an input is invalid and 2 on usage errors.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rs/xid"
+)
+
+// env holds the standard streams of the command, so it can be run from tests.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(e *env, args []string) int
+}
+
+var commands = []command{
+	{"gen", "gen [-n count] [-time time] [-encoding name]", gen},
+	{"inspect", "inspect [-format table|json] [-encoding name] [-registry file] [id ...]", inspect},
+	{"validate", "validate [-q] [-encoding name] [id ...]", validate},
+	{"convert", "convert -field name [-format csv|ndjson] [-from name] [-to name] [-drop]", convert},
+	{"grep", "grep [-o] [-n] [-since time] [-until time]", grep},
+	{"redact", "redact [-mode replace|hash] [-replacement text] [-key-file file] [-since time] [-until time]", redact},
+	{"stats", "stats [-format table|json] [-encoding name] [-max-rate rate] [id ...]", statsCmd},
+}
+
+func main() {
+	os.Exit(run(&env{os.Stdin, os.Stdout, os.Stderr}, os.Args[1:]))
+}
+
+func run(e *env, args []string) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(e, args[1:])
+		}
+	}
+	fmt.Fprintf(e.stderr, "xid: unknown command %q\n", args[0])
+	usage(e.stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  xid %s\n", c.usage)
+	}
+}
+
+// newFlagSet returns the flag set of the command c.
+func newFlagSet(e *env, c string) *flag.FlagSet {
+	fs := flag.NewFlagSet("xid "+c, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+var encodings = []xid.Encoding{
+	xid.Base32HexEncoding,
+	xid.HexEncoding,
+	xid.Base58Encoding,
+	xid.Base62Encoding,
+	xid.Base64URLEncoding,
+	xid.CrockfordEncoding,
+	base64Encoding{},
+	byteaEncoding{},
+}
+
+// encodingNames is the list of the names of the supported encodings, for
+// usage messages.
+func encodingNames() string {
+	names := make([]string, len(encodings))
+	for i, enc := range encodings {
+		names[i] = enc.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// lookupEncoding returns the encoding with the given name.
+func lookupEncoding(name string) (xid.Encoding, error) {
+	for _, enc := range encodings {
+		if enc.Name() == name {
+			return enc, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown encoding %q (supported: %s)", name, encodingNames())
+}
+
+// parser returns the function reading ids in the named encoding. The "any"
+// encoding detects the representations supported by xid.ParseAny.
+func parser(name string) (func(string) (xid.ID, error), error) {
+	if name == "any" {
+		return xid.ParseAny, nil
+	}
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Decode, nil
+}
+
+// readInputs calls fn with each of args, or with each non-empty line of the
+// standard input if args is empty. The position of the input, starting at 1,
+// is passed along.
+func readInputs(e *env, args []string, fn func(n int, s string)) error {
+	if len(args) > 0 {
+		for i, s := range args {
+			fn(i+1, s)
+		}
+		return nil
+	}
+	scanner := bufio.NewScanner(e.stdin)
+	for n := 1; scanner.Scan(); n++ {
+		if s := strings.TrimSpace(scanner.Text()); s != "" {
+			fn(n, s)
+		}
+	}
+	return scanner.Err()
+}
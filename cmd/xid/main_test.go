@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// runCmd runs the command with args and stdin and returns its exit status and
+// outputs.
+func runCmd(stdin string, args ...string) (status int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	status = run(&env{strings.NewReader(stdin), &out, &errOut}, args)
+	return status, out.String(), errOut.String()
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"unknown"}, {"gen", "-unknown"}, {"gen", "-encoding", "base36"}, {"inspect", "-format", "xml"}} {
+		if status, _, stderr := runCmd("", args...); status != 2 || stderr == "" {
+			t.Errorf("%v: status=%d stderr=%q, want status 2 and a message", args, status, stderr)
+		}
+	}
+}
+
+func TestGen(t *testing.T) {
+	status, stdout, _ := runCmd("", "gen", "-n", "3", "-time", "2011-03-22T17:50:19Z", "-encoding", "hex")
+	if status != 0 {
+		t.Fatalf("status=%d, want 0", status)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d ids, want 3", len(lines))
+	}
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "4d88e15b") || len(l) != 24 {
+			t.Errorf("got %q, want an hex id starting with 4d88e15b", l)
+		}
+	}
+	if status, _, _ := runCmd("", "gen", "-time", "-1"); status != 1 {
+		t.Errorf("out of range time: status=%d, want 1", status)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	status, stdout, _ := runCmd("", "inspect", "9m4e2mr0ui3e8a215n4g", `ObjectId("4d88e15b60f486e428412dc9")`)
+	if status != 0 {
+		t.Fatalf("status=%d, want 0", status)
+	}
+	want := "ID                    TIME                  MACHINE  PID    COUNTER\n" +
+		"9m4e2mr0ui3e8a215n4g  2011-03-22T17:50:19Z  60f486   58408  4271561\n" +
+		"9m4e2mr0ui3e8a215n4g  2011-03-22T17:50:19Z  60f486   58408  4271561\n"
+	if stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+}
+
+func TestInspectJSON(t *testing.T) {
+	status, stdout, stderr := runCmd("9m4e2mr0ui3e8a215n4g\n\ninvalid\n", "inspect", "-format", "json")
+	if status != 1 {
+		t.Errorf("status=%d, want 1", status)
+	}
+	want := `{"id":"9m4e2mr0ui3e8a215n4g","time":"2011-03-22T17:50:19Z","machine":"60f486","pid":58408,"counter":4271561}` + "\n"
+	if stdout != want {
+		t.Errorf("got %s, want %s", stdout, want)
+	}
+	if !strings.Contains(stderr, "3:") {
+		t.Errorf("stderr=%q, want the line of the invalid id", stderr)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if status, stdout, _ := runCmd("9m4e2mr0ui3e8a215n4g\n", "validate"); status != 0 || stdout != "" {
+		t.Errorf("status=%d stdout=%q, want 0 and no output", status, stdout)
+	}
+	status, stdout, _ := runCmd("9m4e2mr0ui3e8a215n4g\n4d88e15b60f486e428412dc9\n", "validate")
+	if status != 1 || !strings.HasPrefix(stdout, "2: ") {
+		t.Errorf("status=%d stdout=%q, want 1 and the line of the invalid id", status, stdout)
+	}
+	if status, _, _ := runCmd("", "validate", "-encoding", "any", "4d88e15b60f486e428412dc9"); status != 0 {
+		t.Errorf("status=%d, want 0", status)
+	}
+	if status, stdout, _ := runCmd("", "validate", "-q", "9m4e2mr0ui3e8a215n4z"); status != 1 || stdout != "" {
+		t.Errorf("status=%d stdout=%q, want 1 and no output", status, stdout)
+	}
+}
+
+func TestConvertCSV(t *testing.T) {
+	in := "name,id\n" +
+		"a,9m4e2mr0ui3e8a215n4g\n" +
+		"\"multi\nline\",4d88e15b60f486e428412dc9\n" +
+		"c,\n" +
+		"d,invalid\n" +
+		"e\n"
+	status, stdout, stderr := runCmd(in, "convert", "-field", "id", "-to", "base64")
+	if status != 1 {
+		t.Errorf("status=%d, want 1", status)
+	}
+	want := "name,id\n" +
+		"a,TYjhW2D0huQoQS3J\n" +
+		"\"multi\nline\",TYjhW2D0huQoQS3J\n" +
+		"c,\n" +
+		"d,invalid\n" +
+		"e\n"
+	if stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+	for _, s := range []string{"line 6:", "line 7:", "2 invalid rows"} {
+		if !strings.Contains(stderr, s) {
+			t.Errorf("stderr=%q, want %q", stderr, s)
+		}
+	}
+	status, stdout, _ = runCmd(in, "convert", "-field", "id", "-from", "any", "-to", "bytea", "-drop")
+	want = "name,id\n" +
+		"a,\\x4d88e15b60f486e428412dc9\n" +
+		"\"multi\nline\",\\x4d88e15b60f486e428412dc9\n" +
+		"c,\n"
+	if status != 1 || stdout != want {
+		t.Errorf("status=%d got:\n%s\nwant:\n%s", status, stdout, want)
+	}
+}
+
+func TestConvertCSVRaw(t *testing.T) {
+	status, stdout, _ := runCmd("id\n9m4e2mr0ui3e8a215n4g\n", "convert", "-field", "id", "-to", "raw")
+	if status != 0 || stdout != "id\nM\x88\xe1[`\xf4\x86\xe4(A-\xc9\n" {
+		t.Fatalf("status=%d stdout=%q", status, stdout)
+	}
+	status, back, _ := runCmd(stdout, "convert", "-field", "id", "-from", "raw")
+	if status != 0 || back != "id\n9m4e2mr0ui3e8a215n4g\n" {
+		t.Errorf("status=%d stdout=%q", status, back)
+	}
+	if status, _, _ := runCmd("", "convert", "-field", "id", "-format", "ndjson", "-to", "raw"); status != 2 {
+		t.Errorf("status=%d, want 2", status)
+	}
+}
+
+func TestConvertCSVRawRoundTrip(t *testing.T) {
+	// Raw ids holding line breaks, quotes and commas get quoted in CSV.
+	in := "id,name\n" +
+		"4d88e15b60f486e40d0a2dc9,crlf\n" +
+		"0d0a0d0a0d0a0d0a0d0a0d0a,crlfs\n" +
+		"4d88e15b60f486e40a0d2dc9,lfcr\n" +
+		"222c0d0a222c0a0d22222c2c,quotes\n" +
+		"4d88e15b60f486e428412d0d,cr\n"
+	status, raw, stderr := runCmd(in, "convert", "-field", "id", "-from", "hex", "-to", "raw")
+	if status != 0 {
+		t.Fatalf("status=%d stderr=%q", status, stderr)
+	}
+	status, back, stderr := runCmd(raw, "convert", "-field", "id", "-from", "raw", "-to", "hex")
+	if status != 0 || back != in {
+		t.Errorf("status=%d stderr=%q got:\n%s\nwant:\n%s", status, stderr, back, in)
+	}
+}
+
+func TestConvertCSVMissingColumn(t *testing.T) {
+	status, _, stderr := runCmd("name\na\n", "convert", "-field", "id")
+	if status != 1 || !strings.Contains(stderr, `no "id" column`) {
+		t.Errorf("status=%d stderr=%q", status, stderr)
+	}
+}
+
+func TestConvertCSVBareQuote(t *testing.T) {
+	in := "name,id\n" +
+		"a,9m4e2mr0ui3e8a215n4g\n" +
+		"5\" screen,9m4e2mr0ui3e8a215n4g\n" +
+		"b,9m4e2mr0ui3e8a215n4g\n" +
+		"c,9m4e2mr0ui3e8a215n4g"
+	status, stdout, stderr := runCmd(in, "convert", "-field", "id", "-to", "base64")
+	want := "name,id\n" +
+		"a,TYjhW2D0huQoQS3J\n" +
+		"5\" screen,9m4e2mr0ui3e8a215n4g\n" +
+		"b,TYjhW2D0huQoQS3J\n" +
+		"c,TYjhW2D0huQoQS3J\n"
+	if status != 1 || stdout != want {
+		t.Errorf("status=%d got:\n%s\nwant:\n%s", status, stdout, want)
+	}
+	if !strings.Contains(stderr, "line 3:") || !strings.Contains(stderr, "1 invalid rows") {
+		t.Errorf("stderr=%q, want the row of line 3 reported", stderr)
+	}
+	status, stdout, _ = runCmd(in, "convert", "-field", "id", "-to", "base64", "-drop")
+	want = "name,id\n" +
+		"a,TYjhW2D0huQoQS3J\n" +
+		"b,TYjhW2D0huQoQS3J\n" +
+		"c,TYjhW2D0huQoQS3J\n"
+	if status != 1 || stdout != want {
+		t.Errorf("status=%d got:\n%s\nwant:\n%s", status, stdout, want)
+	}
+}
+
+func TestConvertNDJSON(t *testing.T) {
+	in := `{"user":{"id":"4d88e15b60f486e428412dc9"},"n":12345678901234567890,"html":"<b>"}` + "\n" +
+		`{"user":{"id":null}}` + "\n" +
+		"\n" +
+		`{"user":{"id":"invalid"}}` + "\n" +
+		`{"user":{}}` + "\n" +
+		`{"user":{"id":1}}` + "\n" +
+		`not json` + "\n"
+	status, stdout, stderr := runCmd(in, "convert", "-format", "ndjson", "-field", "user.id", "-from", "hex")
+	if status != 1 {
+		t.Errorf("status=%d, want 1", status)
+	}
+	want := `{"html":"<b>","n":12345678901234567890,"user":{"id":"9m4e2mr0ui3e8a215n4g"}}` + "\n" +
+		`{"user":{"id":null}}` + "\n" +
+		`{"user":{"id":"invalid"}}` + "\n" +
+		`{"user":{}}` + "\n" +
+		`{"user":{"id":1}}` + "\n" +
+		`not json` + "\n"
+	if stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+	for _, s := range []string{"line 4:", "line 5:", "line 6:", "line 7:", "4 invalid rows"} {
+		if !strings.Contains(stderr, s) {
+			t.Errorf("stderr=%q, want %q", stderr, s)
+		}
+	}
+}
+
+func TestGrep(t *testing.T) {
+	in := "start\nreq=9m4e2mr0ui3e8a215n4g parent=vvvvvvvvvvvvvvvvvvvg\nnot an id: 9m4e2mr0ui3e8a215n4h\n"
+	status, stdout, _ := runCmd(in, "grep", "-n")
+	if want := "2:req=9m4e2mr0ui3e8a215n4g parent=vvvvvvvvvvvvvvvvvvvg\n"; status != 0 || stdout != want {
+		t.Errorf("status=%d stdout=%q, want 0 and %q", status, stdout, want)
+	}
+	status, stdout, _ = runCmd(in, "grep", "-o")
+	if want := "9m4e2mr0ui3e8a215n4g\nvvvvvvvvvvvvvvvvvvvg\n"; status != 0 || stdout != want {
+		t.Errorf("status=%d stdout=%q, want 0 and %q", status, stdout, want)
+	}
+	status, stdout, _ = runCmd(in, "grep", "-o", "-since", "2010-01-01T00:00:00Z", "-until", "2030-01-01T00:00:00Z")
+	if want := "9m4e2mr0ui3e8a215n4g\n"; status != 0 || stdout != want {
+		t.Errorf("status=%d stdout=%q, want 0 and %q", status, stdout, want)
+	}
+	if status, stdout, _ := runCmd("no id\n", "grep"); status != 1 || stdout != "" {
+		t.Errorf("status=%d stdout=%q, want 1 and no output", status, stdout)
+	}
+	if status, _, _ := runCmd("", "grep", "-since", "yesterday"); status != 2 {
+		t.Errorf("status=%d, want 2", status)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	in := "req=9m4e2mr0ui3e8a215n4g\nkeep 9m4e2mr0ui3e8a215n4h"
+	status, stdout, _ := runCmd(in, "redact")
+	if want := "req=[xid]\nkeep 9m4e2mr0ui3e8a215n4h"; status != 0 || stdout != want {
+		t.Errorf("status=%d stdout=%q, want 0 and %q", status, stdout, want)
+	}
+	if status, _, stderr := runCmd(in, "redact", "-mode", "hash"); status != 2 || !strings.Contains(stderr, "-key-file") {
+		t.Errorf("status=%d stderr=%q, want 2 without a key", status, stderr)
+	}
+	dir := t.TempDir()
+	key, other := filepath.Join(dir, "key"), filepath.Join(dir, "other")
+	if err := os.WriteFile(key, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("other secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	status, keyed, _ := runCmd(in, "redact", "-mode", "hash", "-key-file", key)
+	if status != 0 || !strings.HasPrefix(keyed, "req=h:") || len(keyed) != len("req=h:\n")+16+len("keep 9m4e2mr0ui3e8a215n4h") {
+		t.Errorf("status=%d stdout=%q, want 0 and a keyed hash", status, keyed)
+	}
+	if _, again, _ := runCmd(in, "redact", "-mode", "hash", "-key-file", key); again != keyed {
+		t.Errorf("got %q, want the same hash %q", again, keyed)
+	}
+	if _, otherKeyed, _ := runCmd(in, "redact", "-mode", "hash", "-key-file", other); otherKeyed == keyed {
+		t.Errorf("got %q with another key, want a different hash", otherKeyed)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := runCmd(in, "redact", "-mode", "hash", "-key-file", empty); status != 2 {
+		t.Errorf("status=%d, want 2 with an empty key", status)
+	}
+}
+
+func TestStats(t *testing.T) {
+	in := "9m4e2mr0ui3e8a215n4g\n9m4e2mr0ui3e8a215n50\n9m4e2mr0ui3e8a215n50\ninvalid\n"
+	status, stdout, stderr := runCmd(in, "stats")
+	if status != 1 || !strings.Contains(stderr, "4:") {
+		t.Errorf("status=%d stderr=%q, want 1 and the line of the invalid id", status, stderr)
+	}
+	want := "MACHINE  PID    COUNT  FIRST                 LAST                  RATE   RESTARTS  DUPLICATES\n" +
+		"60f486   58408  3      2011-03-22T17:50:19Z  2011-03-22T17:50:19Z  2.0/s  0         1\n" +
+		"\n" +
+		"DUPLICATE             COUNT\n" +
+		"9m4e2mr0ui3e8a215n50  2\n"
+	if stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+	status, stdout, _ = runCmd("", "stats", "-format", "json", "9m4e2mr0ui3e8a215n4g")
+	if status != 0 || !strings.Contains(stdout, `"machine": "60f486"`) || !strings.Contains(stdout, `"duplicates": []`) {
+		t.Errorf("status=%d stdout=%s", status, stdout)
+	}
+}
+
+func TestInspectRegistry(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(registry, []byte("web1\nweb2 other\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	web1 := xid.MachineIDFor("web1")
+	id := xid.NewGenerator(xid.WithMachineID(web1), xid.WithPid(1), xid.WithCounter(1)).NewWithTime(time.Unix(1300816219, 0))
+	status, stdout, _ := runCmd("", "inspect", "-registry", registry, id.String(), "9m4e2mr0ui3e8a215n4g")
+	want := "ID                    TIME                  MACHINE  PID    COUNTER  HOSTS\n" +
+		id.String() + "  2011-03-22T17:50:19Z  " + hex.EncodeToString(web1[:]) + "   1      1        web1\n" +
+		"9m4e2mr0ui3e8a215n4g  2011-03-22T17:50:19Z  60f486   58408  4271561  -\n"
+	if status != 0 || stdout != want {
+		t.Errorf("status=%d got:\n%s\nwant:\n%s", status, stdout, want)
+	}
+	status, stdout, _ = runCmd("", "inspect", "-registry", registry, "-format", "json", id.String())
+	if status != 0 || !strings.Contains(stdout, `"hosts":["web1"]`) {
+		t.Errorf("status=%d stdout=%s", status, stdout)
+	}
+	if status, _, _ := runCmd("", "inspect", "-registry", filepath.Join(t.TempDir(), "missing"), id.String()); status != 2 {
+		t.Errorf("status=%d, want 2", status)
+	}
+}
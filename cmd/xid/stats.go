@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/xid/stats"
+)
+
+// statsReport is the JSON representation of a stats.Report.
+type statsReport struct {
+	Count      int              `json:"count"`
+	Groups     []statsGroup     `json:"groups"`
+	Duplicates []statsDuplicate `json:"duplicates"`
+}
+
+type statsGroup struct {
+	Machine    string     `json:"machine"`
+	Pid        uint16     `json:"pid"`
+	Count      int        `json:"count"`
+	First      time.Time  `json:"first"`
+	Last       time.Time  `json:"last"`
+	Rate       float64    `json:"rate"`
+	Restarts   int        `json:"restarts"`
+	Runs       []statsRun `json:"runs"`
+	Duplicates int        `json:"duplicates"`
+}
+
+type statsRun struct {
+	First     time.Time `json:"first"`
+	Last      time.Time `json:"last"`
+	Count     int       `json:"count"`
+	Generated int       `json:"generated"`
+	Rate      float64   `json:"rate"`
+}
+
+type statsDuplicate struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+func newStatsReport(r *stats.Report) statsReport {
+	out := statsReport{
+		Count:      r.Count,
+		Groups:     make([]statsGroup, 0, len(r.Groups)),
+		Duplicates: make([]statsDuplicate, 0, len(r.Duplicates)),
+	}
+	for _, g := range r.Groups {
+		sg := statsGroup{
+			Machine:    hex.EncodeToString(g.Machine[:]),
+			Pid:        g.Pid,
+			Count:      g.Count,
+			First:      g.First.UTC(),
+			Last:       g.Last.UTC(),
+			Rate:       g.Rate,
+			Restarts:   g.Restarts(),
+			Duplicates: g.Duplicates,
+		}
+		for _, run := range g.Runs {
+			sg.Runs = append(sg.Runs, statsRun{
+				First:     run.First.UTC(),
+				Last:      run.Last.UTC(),
+				Count:     run.Count,
+				Generated: run.Generated,
+				Rate:      run.Rate,
+			})
+		}
+		out.Groups = append(out.Groups, sg)
+	}
+	for _, d := range r.Duplicates {
+		out.Duplicates = append(out.Duplicates, statsDuplicate{ID: d.ID.String(), Count: d.Count})
+	}
+	return out
+}
+
+func statsCmd(e *env, args []string) int {
+	fs := newFlagSet(e, "stats")
+	format := fs.String("format", "table", "output format: table or json")
+	encName := fs.String("encoding", "any", "input encoding: any, "+encodingNames())
+	maxRate := fs.Float64("max-rate", stats.DefaultMaxRate, "highest plausible generation rate of a process, in ids per second, used to detect restarts")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	parse, err := parser(*encName)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid stats: %v\n", err)
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(e.stderr, "xid stats: unknown format %q\n", *format)
+		return 2
+	}
+	a := stats.Analyzer{MaxRate: *maxRate}
+	status := 0
+	err = readInputs(e, fs.Args(), func(n int, s string) {
+		id, err := parse(s)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "xid stats: %d: %v\n", n, err)
+			status = 1
+			return
+		}
+		a.Add(id)
+	})
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid stats: %v\n", err)
+		return 1
+	}
+	r := newStatsReport(a.Report())
+	if *format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(r)
+		return status
+	}
+	w := tabwriter.NewWriter(e.stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "MACHINE\tPID\tCOUNT\tFIRST\tLAST\tRATE\tRESTARTS\tDUPLICATES")
+	for _, g := range r.Groups {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%.1f/s\t%d\t%d\n", g.Machine, g.Pid, g.Count,
+			g.First.Format(time.RFC3339), g.Last.Format(time.RFC3339), g.Rate, g.Restarts, g.Duplicates)
+	}
+	if len(r.Duplicates) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "DUPLICATE\tCOUNT")
+		for _, d := range r.Duplicates {
+			fmt.Fprintf(w, "%s\t%d\n", d.ID, d.Count)
+		}
+	}
+	w.Flush()
+	return status
+}
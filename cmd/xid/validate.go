@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+)
+
+func validate(e *env, args []string) int {
+	fs := newFlagSet(e, "validate")
+	quiet := fs.Bool("q", false, "don't report invalid ids, only set the exit status")
+	encName := fs.String("encoding", "base32hex", "input encoding: any, "+encodingNames())
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	parse, err := parser(*encName)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid validate: %v\n", err)
+		return 2
+	}
+	status := 0
+	err = readInputs(e, fs.Args(), func(n int, s string) {
+		if _, err := parse(s); err != nil {
+			status = 1
+			if !*quiet {
+				fmt.Fprintf(e.stdout, "%d: %v\n", n, err)
+			}
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(e.stderr, "xid validate: %v\n", err)
+		return 1
+	}
+	return status
+}
@@ -0,0 +1,256 @@
+// Package cursor implements keyset pagination over xid keys.
+//
+// A Cursor holds the last ID seen and the direction of the pagination. It
+// selects the IDs strictly greater (After) or lower (Before) than its ID, so
+// pages never overlap or skip rows, even when many rows share the same second:
+//
+//	c, err := cursor.Parse(r.URL.Query().Get("cursor"))
+//	query := "SELECT id, name FROM users"
+//	if op := c.Comparison(); op != "" {
+//		query += " WHERE id " + op + " $1"
+//	}
+//	query += " ORDER BY id " + c.Order() + " LIMIT 21"
+//	// read the ids of the rows, then:
+//	page := cursor.NewPage(c, ids, 20)
+//	// return the page.Len first rows and page.Next if page.HasNext
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// ErrInvalidCursor is returned when parsing a malformed cursor.
+var ErrInvalidCursor = errors.New("xid: invalid cursor")
+
+// Direction is the direction a cursor reads IDs in.
+type Direction byte
+
+const (
+	// After reads the IDs greater than the cursor, in ascending order.
+	After Direction = iota
+	// Before reads the IDs lower than the cursor, in descending order.
+	Before
+)
+
+// Reverse returns the opposite direction.
+func (d Direction) Reverse() Direction {
+	if d == Before {
+		return After
+	}
+	return Before
+}
+
+// String returns the name of the direction.
+func (d Direction) String() string {
+	if d == Before {
+		return "before"
+	}
+	return "after"
+}
+
+// encodedLen is the length of the binary representation of a cursor: the
+// direction followed by the raw id.
+const encodedLen = 1 + 12
+
+// Cursor is a position in a list of IDs and the direction to read it in.
+//
+// A cursor with a nil ID is a start cursor: it reads all the IDs, from the
+// first one with After and from the last one with Before. The zero value is
+// the start cursor reading IDs in ascending order.
+type Cursor struct {
+	ID        xid.ID
+	Direction Direction
+}
+
+// Since returns a cursor reading the IDs generated from the second of t
+// included, in ascending order. It returns an empty cursor if t is after
+// MaxTime.
+func Since(t time.Time) Cursor {
+	if t.Unix() <= xid.MinTime.Unix() {
+		return Cursor{Direction: After}
+	}
+	return Cursor{ID: xid.MaxID(t.Add(-time.Second)), Direction: After}
+}
+
+// Until returns a cursor reading the IDs generated up to the second of t
+// included, in descending order. It returns an empty cursor if t is before
+// MinTime.
+func Until(t time.Time) Cursor {
+	switch {
+	case t.Unix() >= xid.MaxTime.Unix():
+		return Cursor{Direction: Before}
+	case t.Unix() < xid.MinTime.Unix():
+		return Cursor{ID: firstID, Direction: Before}
+	}
+	return Cursor{ID: xid.MinID(t.Add(time.Second)), Direction: Before}
+}
+
+// firstID and lastID are the smallest and largest non-nil IDs, the bounds of
+// the empty cursors.
+var (
+	firstID = xid.ID{11: 1}
+	lastID  = xid.MaxID(xid.MaxTime)
+)
+
+// IsStart returns true if the cursor reads the list from its beginning.
+func (c Cursor) IsStart() bool {
+	return c.ID.IsNil()
+}
+
+// IsEmpty returns true if the cursor selects no IDs: it reads either the IDs
+// after the largest one or the IDs before the smallest non-nil one. Such a
+// cursor still has a Comparison, so its query selects no rows.
+func (c Cursor) IsEmpty() bool {
+	if c.Direction == Before {
+		return c.ID == firstID
+	}
+	return c.ID == lastID
+}
+
+// Time returns the timestamp of the ID of the cursor.
+func (c Cursor) Time() time.Time {
+	return c.ID.Time()
+}
+
+// Comparison returns the operator comparing the keys to the ID of the cursor
+// to select the following IDs: ">" for After and "<" for Before. It returns an
+// empty string for a start cursor, which selects all the IDs.
+func (c Cursor) Comparison() string {
+	switch {
+	case c.IsStart():
+		return ""
+	case c.Direction == Before:
+		return "<"
+	}
+	return ">"
+}
+
+// Order returns the order to sort the keys in: "ASC" for After and "DESC"
+// for Before.
+func (c Cursor) Order() string {
+	if c.Direction == Before {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// Match reports whether id comes after the cursor in its direction, i.e.
+// whether it's selected by Comparison.
+func (c Cursor) Match(id xid.ID) bool {
+	switch {
+	case c.IsStart():
+		return true
+	case c.Direction == Before:
+		return id.Compare(c.ID) < 0
+	}
+	return id.Compare(c.ID) > 0
+}
+
+// Less reports whether a comes before b in the direction of the cursor, i.e.
+// in the order given by Order.
+func (c Cursor) Less(a, b xid.ID) bool {
+	if c.Direction == Before {
+		return a.Compare(b) > 0
+	}
+	return a.Compare(b) < 0
+}
+
+// String returns the opaque representation of the cursor, safe to use in
+// URLs.
+func (c Cursor) String() string {
+	text, _ := c.MarshalText()
+	return string(text)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Cursor) MarshalText() ([]byte, error) {
+	var raw [encodedLen]byte
+	raw[0] = byte(c.Direction)
+	copy(raw[1:], c.ID[:])
+	text := make([]byte, base64.RawURLEncoding.EncodedLen(encodedLen))
+	base64.RawURLEncoding.Encode(text, raw[:])
+	return text, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Cursor) UnmarshalText(text []byte) error {
+	var raw [encodedLen]byte
+	if base64.RawURLEncoding.DecodedLen(len(text)) != encodedLen {
+		return ErrInvalidCursor
+	}
+	if _, err := base64.RawURLEncoding.Strict().Decode(raw[:], text); err != nil {
+		return ErrInvalidCursor
+	}
+	d := Direction(raw[0])
+	if d != After && d != Before {
+		return ErrInvalidCursor
+	}
+	id, err := xid.FromBytes(raw[1:])
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	*c = Cursor{ID: id, Direction: d}
+	return nil
+}
+
+// Parse reads a cursor from its opaque representation. An empty string is
+// the zero Cursor, so a missing cursor parameter starts the pagination.
+func Parse(s string) (Cursor, error) {
+	var c Cursor
+	if s == "" {
+		return c, nil
+	}
+	err := c.UnmarshalText([]byte(s))
+	return c, err
+}
+
+// Page holds the boundaries of a page of results read from a cursor.
+type Page struct {
+	// Len is the number of results in the page.
+	Len int
+
+	// Next reads the page following this one, in the same direction. It's
+	// only set if HasNext is true.
+	Next    Cursor
+	HasNext bool
+
+	// Prev reads the page preceding this one, in the reverse direction. It's
+	// only set if HasPrev is true.
+	Prev    Cursor
+	HasPrev bool
+}
+
+// NewPage returns the boundaries of a page of size results read from c. The
+// ids are the keys of the results selected with Comparison, sorted by Order
+// and limited to size+1 results: the extra result, if any, tells there is a
+// next page and isn't part of the page. A size lower than 1 is taken as 1.
+//
+// The IDs of a page read with Before are in descending order. Pages are
+// usually displayed in ascending order, so the results of such a page must be
+// reversed after the page is computed.
+func NewPage(c Cursor, ids []xid.ID, size int) Page {
+	if size < 1 {
+		size = 1
+	}
+	p := Page{Len: len(ids)}
+	if p.Len > size {
+		p.Len = size
+		p.HasNext = true
+	}
+	if p.Len == 0 {
+		p.HasNext = false
+		return p
+	}
+	if p.HasNext {
+		p.Next = Cursor{ID: ids[p.Len-1], Direction: c.Direction}
+	}
+	if !c.IsStart() {
+		p.Prev = Cursor{ID: ids[0], Direction: c.Direction.Reverse()}
+		p.HasPrev = true
+	}
+	return p
+}
@@ -0,0 +1,178 @@
+package cursor
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+var start = time.Unix(1300816219, 0)
+
+// testIDs returns 10 sorted ids, generated within 3 seconds.
+func testIDs() []xid.ID {
+	clock := xid.NewManualClock(start)
+	g := xid.NewGenerator(xid.WithClock(clock))
+	ids := make([]xid.ID, 10)
+	for i := range ids {
+		if i%4 == 3 {
+			clock.Advance(time.Second)
+		}
+		ids[i] = g.New()
+	}
+	return ids
+}
+
+// query simulates a keyset pagination query on ids.
+func query(ids []xid.ID, c Cursor, limit int) []xid.ID {
+	var res []xid.ID
+	for _, id := range ids {
+		if c.Match(id) {
+			res = append(res, id)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return c.Less(res[i], res[j]) })
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res
+}
+
+func TestPaginate(t *testing.T) {
+	ids := testIDs()
+	for _, d := range []Direction{After, Before} {
+		var got []xid.ID
+		c := Cursor{Direction: d}
+		for pages := 0; ; pages++ {
+			if pages > len(ids) {
+				t.Fatalf("%v: pagination doesn't end", d)
+			}
+			res := query(ids, c, 4)
+			p := NewPage(c, res, 3)
+			got = append(got, res[:p.Len]...)
+			if !p.HasNext {
+				break
+			}
+			if next, err := Parse(p.Next.String()); err != nil || next != p.Next {
+				t.Fatalf("Parse(%v) = %v, %v, want %v", p.Next, next, err, p.Next)
+			}
+			c = p.Next
+		}
+		want := append([]xid.ID(nil), ids...)
+		if d == Before {
+			sort.Slice(want, func(i, j int) bool { return want[i].Compare(want[j]) > 0 })
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: got %v, want %v", d, got, want)
+		}
+	}
+}
+
+func TestPagePrev(t *testing.T) {
+	ids := testIDs()
+	first := NewPage(Cursor{}, query(ids, Cursor{}, 4), 3)
+	if first.HasPrev {
+		t.Errorf("first page HasPrev = true, want false")
+	}
+	second := NewPage(first.Next, query(ids, first.Next, 4), 3)
+	if !second.HasPrev {
+		t.Fatal("second page HasPrev = false, want true")
+	}
+	prev := query(ids, second.Prev, 4)
+	p := NewPage(second.Prev, prev, 3)
+	want := []xid.ID{ids[2], ids[1], ids[0]}
+	if got := prev[:p.Len]; !reflect.DeepEqual(got, want) {
+		t.Errorf("previous page = %v, want %v", got, want)
+	}
+	if p.HasNext {
+		t.Errorf("previous page HasNext = true, want false")
+	}
+}
+
+func TestNewPageEmpty(t *testing.T) {
+	c := Cursor{ID: xid.New()}
+	if got, want := NewPage(c, nil, 3), (Page{}); got != want {
+		t.Errorf("NewPage() = %v, want %v", got, want)
+	}
+}
+
+func TestNewPageSize(t *testing.T) {
+	ids := testIDs()
+	c := Cursor{ID: ids[0]}
+	want := Page{Len: 1, Next: Cursor{ID: ids[1]}, HasNext: true, Prev: Cursor{ID: ids[1], Direction: Before}, HasPrev: true}
+	for _, size := range []int{-2, 0, 1} {
+		if got := NewPage(c, ids[1:3], size); got != want {
+			t.Errorf("NewPage(size=%d) = %v, want %v", size, got, want)
+		}
+	}
+	if got := NewPage(c, ids[1:2], 0); got.Len != 1 || got.HasNext {
+		t.Errorf("NewPage(size=0) = %v, want a last page of 1 id", got)
+	}
+}
+
+func TestSinceUntil(t *testing.T) {
+	ids := testIDs()
+	second := start.Add(time.Second + 500*time.Millisecond)
+	if got, want := query(ids, Since(second), 10), ids[3:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Since() = %v, want %v", got, want)
+	}
+	if got, want := query(ids, Until(second), 10), []xid.ID{ids[6], ids[5], ids[4], ids[3], ids[2], ids[1], ids[0]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Until() = %v, want %v", got, want)
+	}
+	if c := Since(time.Unix(-1, 0)); !c.IsStart() || c.Direction != After {
+		t.Errorf("Since() = %v, want a start cursor", c)
+	}
+	if c := Until(xid.MaxTime); !c.IsStart() || c.Direction != Before {
+		t.Errorf("Until() = %v, want a start cursor", c)
+	}
+	for _, c := range []Cursor{Until(time.Unix(-1, 0)), Since(xid.MaxTime.Add(time.Second))} {
+		if c.IsStart() || !c.IsEmpty() || c.Comparison() == "" {
+			t.Errorf("cursor %v: IsStart()=%v IsEmpty()=%v, want an empty cursor", c, c.IsStart(), c.IsEmpty())
+		}
+		if got := query(ids, c, 10); len(got) != 0 {
+			t.Errorf("cursor %v selects %v, want no ids", c, got)
+		}
+	}
+	if Since(second).IsEmpty() || Until(second).IsEmpty() || (Cursor{}).IsEmpty() {
+		t.Error("IsEmpty() = true, want false")
+	}
+}
+
+func TestComparison(t *testing.T) {
+	id := xid.New()
+	tests := []struct {
+		c          Cursor
+		comparison string
+		order      string
+	}{
+		{Cursor{}, "", "ASC"},
+		{Cursor{Direction: Before}, "", "DESC"},
+		{Cursor{ID: id}, ">", "ASC"},
+		{Cursor{ID: id, Direction: Before}, "<", "DESC"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Comparison(); got != tt.comparison {
+			t.Errorf("%v.Comparison() = %q, want %q", tt.c, got, tt.comparison)
+		}
+		if got := tt.c.Order(); got != tt.order {
+			t.Errorf("%v.Order() = %q, want %q", tt.c, got, tt.order)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	c := Cursor{ID: xid.New(), Direction: Before}
+	if got, err := Parse(c.String()); err != nil || got != c {
+		t.Errorf("Parse(%q) = %v, %v, want %v", c.String(), got, err, c)
+	}
+	if got, err := Parse(""); err != nil || got != (Cursor{}) {
+		t.Errorf("Parse(\"\") = %v, %v, want the zero cursor", got, err)
+	}
+	for _, s := range []string{"abc", "Ak2I4VtggIbkKEEtyQ", "Ak2I4VtggIbkKEEtyQ=", "#k2I4VtggIbkKEEtyQ"} {
+		if _, err := Parse(s); err != ErrInvalidCursor {
+			t.Errorf("Parse(%q) err=%v, want %v", s, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package xid
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// Encoding is a string representation of IDs.
+type Encoding interface {
+	// Name returns the name of the encoding.
+	Name() string
+
+	// Encode returns the representation of id.
+	Encode(id ID) string
+
+	// Decode reads an ID from its representation.
+	Decode(s string) (ID, error)
+
+	// Sortable reports whether the lexicographic order of the encoded ids
+	// is the same as the order of the ids (see Compare).
+	Sortable() bool
+}
+
+var (
+	// Base32HexEncoding is the default xid encoding: 20 chars of lowercase
+	// base32 hex without padding. It's sortable.
+	Base32HexEncoding Encoding = base32HexEncoding{}
+
+	// HexEncoding is the 24 chars lowercase hex encoding used by MongoDB
+	// ObjectIds. It's sortable.
+	HexEncoding Encoding = hexEncoding{}
+
+	// Base58Encoding is the base58 encoding using the Bitcoin alphabet. Its
+	// length varies from 12 to 17 chars and it's not sortable.
+	Base58Encoding Encoding = newBaseNEncoding("base58", "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", 0)
+
+	// Base62Encoding is a 17 chars base62 encoding using the 0-9A-Za-z
+	// alphabet, left padded with zeros. It's sortable.
+	Base62Encoding Encoding = newBaseNEncoding("base62", "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 17)
+
+	// Base64URLEncoding is the 16 chars URL safe base64 encoding without
+	// padding (RFC 4648). It's not sortable.
+	Base64URLEncoding Encoding = base64URLEncoding{}
+
+	// CrockfordEncoding is the 20 chars Crockford's base32 encoding. It
+	// encodes with uppercase letters and decodes case-insensitively, reading
+	// I and L as 1 and O as 0. It's sortable.
+	CrockfordEncoding Encoding = crockfordEncoding{}
+)
+
+// Format returns the representation of the id in the enc encoding.
+func (id ID) Format(enc Encoding) string {
+	return enc.Encode(id)
+}
+
+// Parse reads an ID from its representation in the enc encoding.
+func Parse(enc Encoding, s string) (ID, error) {
+	return enc.Decode(s)
+}
+
+type base32HexEncoding struct{}
+
+func (base32HexEncoding) Name() string                { return "base32hex" }
+func (base32HexEncoding) Encode(id ID) string         { return id.String() }
+func (base32HexEncoding) Decode(s string) (ID, error) { return FromString(s) }
+func (base32HexEncoding) Sortable() bool              { return true }
+
+type hexEncoding struct{}
+
+func (hexEncoding) Name() string                { return "hex" }
+func (hexEncoding) Encode(id ID) string         { return id.Hex() }
+func (hexEncoding) Decode(s string) (ID, error) { return FromHex(s) }
+func (hexEncoding) Sortable() bool              { return true }
+
+type base64URLEncoding struct{}
+
+func (base64URLEncoding) Name() string { return "base64url" }
+
+func (base64URLEncoding) Encode(id ID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+func (base64URLEncoding) Decode(s string) (ID, error) {
+	b, err := base64.RawURLEncoding.Strict().DecodeString(s)
+	if err != nil {
+		return nilID, ErrInvalidID
+	}
+	return FromBytes(b)
+}
+
+func (base64URLEncoding) Sortable() bool { return false }
+
+// crockfordAlphabet maps the digits of the xid base32 encoding to the
+// Crockford's base32 alphabet. Both alphabets encode the same bits, so ids are
+// translated char by char.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// crockfordDec maps Crockford's base32 chars, including lowercase and
+// aliases, to the chars of the xid base32 encoding.
+var crockfordDec [256]byte
+
+func init() {
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		c := crockfordAlphabet[i]
+		crockfordDec[c] = encoding[i]
+		crockfordDec[strings.ToLower(string(c))[0]] = encoding[i]
+	}
+	for _, c := range "oO" {
+		crockfordDec[c] = encoding[0]
+	}
+	for _, c := range "iIlL" {
+		crockfordDec[c] = encoding[1]
+	}
+}
+
+type crockfordEncoding struct{}
+
+func (crockfordEncoding) Name() string { return "crockford" }
+
+func (crockfordEncoding) Encode(id ID) string {
+	text := make([]byte, encodedLen)
+	encode(text, id[:])
+	for i, c := range text {
+		text[i] = crockfordAlphabet[dec[c]]
+	}
+	return string(text)
+}
+
+func (crockfordEncoding) Decode(s string) (ID, error) {
+	text := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		if text[i] = crockfordDec[s[i]]; text[i] == 0 {
+			text[i] = 0xFF
+		}
+	}
+	id, err := FromString(string(text))
+	if err != nil {
+		// Report the error on the original input
+		err.(*ParseError).Input = s
+	}
+	return id, err
+}
+
+func (crockfordEncoding) Sortable() bool { return true }
+
+// baseNEncoding encodes ids as a big-endian number written in base
+// len(alphabet). When width is set, encoded ids are left padded with the
+// first char of the alphabet, which keeps them sortable if the alphabet is
+// sorted. Otherwise, each leading zero byte of the id is written as the first
+// char of the alphabet followed by the number without padding, as done by
+// base58.
+type baseNEncoding struct {
+	name     string
+	alphabet string
+	width    int
+	dec      [256]byte
+}
+
+func newBaseNEncoding(name, alphabet string, width int) *baseNEncoding {
+	enc := &baseNEncoding{name: name, alphabet: alphabet, width: width}
+	for i := range enc.dec {
+		enc.dec[i] = 0xFF
+	}
+	for i := 0; i < len(alphabet); i++ {
+		enc.dec[alphabet[i]] = byte(i)
+	}
+	return enc
+}
+
+func (enc *baseNEncoding) Name() string { return enc.name }
+
+func (enc *baseNEncoding) Sortable() bool { return enc.width > 0 }
+
+func (enc *baseNEncoding) Encode(id ID) string {
+	base := byte(len(enc.alphabet))
+	var buf [rawLen * 2]byte
+	n := id
+	i := len(buf)
+	for !n.IsNil() {
+		i--
+		buf[i] = enc.alphabet[divmod(n[:], base)]
+	}
+	if enc.width > 0 {
+		for i > len(buf)-enc.width {
+			i--
+			buf[i] = enc.alphabet[0]
+		}
+		return string(buf[i:])
+	}
+	for j := 0; j < rawLen && id[j] == 0; j++ {
+		i--
+		buf[i] = enc.alphabet[0]
+	}
+	return string(buf[i:])
+}
+
+func (enc *baseNEncoding) Decode(s string) (ID, error) {
+	var id ID
+	if s == "" || len(s) > 2*rawLen || (enc.width > 0 && len(s) != enc.width) {
+		return nilID, ErrInvalidID
+	}
+	base := byte(len(enc.alphabet))
+	for i := 0; i < len(s); i++ {
+		d := enc.dec[s[i]]
+		if d == 0xFF || mulAdd(id[:], base, d) {
+			return nilID, ErrInvalidID
+		}
+	}
+	if enc.width == 0 && enc.Encode(id) != s {
+		// Reject non canonical representations, i.e. with extra leading
+		// zeros.
+		return nilID, ErrInvalidID
+	}
+	return id, nil
+}
+
+// divmod divides the big-endian number n by d in place and returns the
+// remainder.
+func divmod(n []byte, d byte) byte {
+	var r uint
+	for i, b := range n {
+		r = r<<8 | uint(b)
+		n[i] = byte(r / uint(d))
+		r %= uint(d)
+	}
+	return byte(r)
+}
+
+// mulAdd sets the big-endian number n to n*m+a in place and reports whether
+// the result overflowed.
+func mulAdd(n []byte, m, a byte) (overflow bool) {
+	carry := uint(a)
+	for i := len(n) - 1; i >= 0; i-- {
+		v := uint(n[i])*uint(m) + carry
+		n[i] = byte(v)
+		carry = v >> 8
+	}
+	return carry != 0
+}
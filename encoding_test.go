@@ -0,0 +1,140 @@
+package xid
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var allEncodings = []Encoding{
+	Base32HexEncoding,
+	HexEncoding,
+	Base58Encoding,
+	Base62Encoding,
+	Base64URLEncoding,
+	CrockfordEncoding,
+}
+
+func TestEncodingFormat(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, tt := range []struct {
+		enc  Encoding
+		want string
+	}{
+		{Base32HexEncoding, "9m4e2mr0ui3e8a215n4g"},
+		{HexEncoding, "4d88e15b60f486e428412dc9"},
+		{Base58Encoding, "2Ts2QqgNRKo7VGnKA"},
+		{Base62Encoding, "0VCs04xTJMQCMA3B3"},
+		{Base64URLEncoding, "TYjhW2D0huQoQS3J"},
+		{CrockfordEncoding, "9P4E2PV0YJ3E8A215Q4G"},
+	} {
+		if got := id.Format(tt.enc); got != tt.want {
+			t.Errorf("Format(%s) = %v, want %v", tt.enc.Name(), got, tt.want)
+		}
+		got, err := Parse(tt.enc, tt.want)
+		if err != nil {
+			t.Errorf("Parse(%s, %q) err=%v", tt.enc.Name(), tt.want, err)
+		}
+		if got != id {
+			t.Errorf("Parse(%s, %q) = %v, want %v", tt.enc.Name(), tt.want, got, id)
+		}
+	}
+}
+
+func TestEncodingEdgeValues(t *testing.T) {
+	for _, tt := range []struct {
+		enc  Encoding
+		id   ID
+		want string
+	}{
+		{Base58Encoding, ID{}, "111111111111"},
+		{Base58Encoding, ID{0, 0, 0, 1}, "111jpXCZedGfVR"},
+		{Base62Encoding, ID{}, "00000000000000000"},
+		{Base62Encoding, ID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "1f2SI9UJPXvb7vdJ1"},
+	} {
+		if got := tt.id.Format(tt.enc); got != tt.want {
+			t.Errorf("Format(%s) = %v, want %v", tt.enc.Name(), got, tt.want)
+		}
+		if got, err := Parse(tt.enc, tt.want); err != nil || got != tt.id {
+			t.Errorf("Parse(%s, %q) = %v, %v, want %v", tt.enc.Name(), tt.want, got, err, tt.id)
+		}
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, enc := range allEncodings {
+		for i := 0; i < 1000; i++ {
+			var id ID
+			r.Read(id[:])
+			// Exercise leading zero bytes
+			for j := 0; j < r.Intn(4); j++ {
+				id[j] = 0
+			}
+			s := id.Format(enc)
+			got, err := Parse(enc, s)
+			if err != nil {
+				t.Fatalf("Parse(%s, %q) err=%v", enc.Name(), s, err)
+			}
+			if got != id {
+				t.Fatalf("Parse(%s, %q) = %v, want %v", enc.Name(), s, got, id)
+			}
+		}
+	}
+}
+
+func TestEncodingSortable(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ids := make([]ID, 1000)
+	for i := range ids {
+		r.Read(ids[i][:])
+	}
+	Sort(ids)
+	for _, enc := range allEncodings {
+		if !enc.Sortable() {
+			continue
+		}
+		strs := make([]string, len(ids))
+		for i, id := range ids {
+			strs[i] = id.Format(enc)
+		}
+		if !sort.StringsAreSorted(strs) {
+			t.Errorf("%s encoding is not sortable", enc.Name())
+		}
+	}
+}
+
+func TestEncodingDecodeInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		enc Encoding
+		s   string
+	}{
+		{Base58Encoding, ""},
+		{Base58Encoding, "0Ts2QqgNRKo7VGnKA"},
+		{Base58Encoding, "12Ts2QqgNRKo7VGnKA"},
+		{Base58Encoding, "zzzzzzzzzzzzzzzzz"},
+		{Base62Encoding, "VCs04xTJMQCMA3B3"},
+		{Base62Encoding, "zzzzzzzzzzzzzzzzz"},
+		{Base62Encoding, "0VCs04xTJMQCMA3B-"},
+		{Base64URLEncoding, "TYjhW2D0huQoQS3"},
+		{Base64URLEncoding, "TYjhW2D0huQoQS3J=="},
+		{Base64URLEncoding, "TYjhW2D0huQoQS3+"},
+		{CrockfordEncoding, "9P4E2PV0YJ3E8A215Q4"},
+		{CrockfordEncoding, "9P4E2PV0YJ3E8A215Q4U"},
+		{CrockfordEncoding, "9P4E2PV0YJ3E8A215Q4H"},
+	} {
+		if _, err := Parse(tt.enc, tt.s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Parse(%s, %q) err=%v, want %v", tt.enc.Name(), tt.s, err, ErrInvalidID)
+		}
+	}
+}
+
+func TestCrockfordDecodeAliases(t *testing.T) {
+	want := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, s := range []string{"9p4e2pv0yj3e8a215q4g", "9P4E2PVOYJ3E8A2I5Q4G", "9P4E2PVoYJ3E8A2l5Q4G"} {
+		if got, err := Parse(CrockfordEncoding, s); err != nil || got != want {
+			t.Errorf("Parse(crockford, %q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+}
@@ -1,10 +1,93 @@
 package xid
 
+import (
+	"fmt"
+	"time"
+)
+
 const (
 	// ErrInvalidID is returned when trying to unmarshal an invalid ID.
 	ErrInvalidID strErr = "xid: invalid ID"
+
+	// ErrClockRollback is returned when the clock of a generator went
+	// backwards and its rollback policy is RollbackError.
+	ErrClockRollback strErr = "xid: clock moved backwards"
+
+	// ErrCounterExhausted is returned when all the counter values of a
+	// generator have been used within the same second.
+	ErrCounterExhausted strErr = "xid: counter exhausted"
+
+	// ErrStateLocked is returned when opening a state file locked by another
+	// process.
+	ErrStateLocked strErr = "xid: state file locked"
+
+	// ErrInvalidArray is returned when scanning a malformed PostgreSQL array
+	// literal.
+	ErrInvalidArray strErr = "xid: invalid array"
+)
+
+// ParseErrorReason tells why an input is not a valid ID.
+type ParseErrorReason int
+
+const (
+	// ReasonLength means the input doesn't have the expected length.
+	ReasonLength ParseErrorReason = iota + 1
+
+	// ReasonChar means the input contains an unexpected char.
+	ReasonChar
+
+	// ReasonNonCanonical means the last char of a base32 input sets bits
+	// beyond the 12 bytes of an ID, so it's not the canonical representation
+	// of an ID.
+	ReasonNonCanonical
 )
 
+func (r ParseErrorReason) String() string {
+	switch r {
+	case ReasonLength:
+		return "invalid length"
+	case ReasonChar:
+		return "invalid char"
+	case ReasonNonCanonical:
+		return "non canonical last char"
+	}
+	return "unknown reason"
+}
+
+// ParseError is returned when an input can't be read as an ID. It wraps
+// ErrInvalidID so errors.Is(err, ErrInvalidID) holds.
+type ParseError struct {
+	// Input is the input being parsed.
+	Input string
+
+	// Offset is the byte offset in Input where the error was detected. For
+	// length errors, it's the offset where the input was expected to end.
+	Offset int
+
+	// Reason tells why the input is invalid.
+	Reason ParseErrorReason
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s at offset %d in %q", ErrInvalidID, err.Reason, err.Offset, err.Input)
+}
+
+// Unwrap returns ErrInvalidID.
+func (err *ParseError) Unwrap() error {
+	return ErrInvalidID
+}
+
+// TimeRangeError is returned when generating an ID with a time that can't be
+// represented by the 4-byte timestamp, i.e. outside of the [MinTime, MaxTime]
+// range.
+type TimeRangeError struct {
+	Time time.Time
+}
+
+func (err *TimeRangeError) Error() string {
+	return fmt.Sprintf("xid: time %v out of range [%v, %v]", err.Time, MinTime, MaxTime)
+}
+
 // strErr allows declaring errors as constants.
 type strErr string
 
@@ -0,0 +1,79 @@
+package xid
+
+// Match is an ID found in a text.
+type Match struct {
+	ID    ID
+	Start int // offset of the first char of the id in the text
+	End   int // offset following the last char of the id in the text
+}
+
+// FindOption tunes the behavior of FindAll and ReplaceAll.
+type FindOption func(*finder)
+
+type finder struct {
+	r      IDRange
+	filter bool
+}
+
+// FindInRange only reports the IDs within r. It filters out the words that
+// happen to be valid IDs but have an implausible timestamp, i.e.:
+//
+//	xid.FindAll(text, xid.FindInRange(xid.NewIDRange(deployedAt, time.Now())))
+func FindInRange(r IDRange) FindOption {
+	return func(f *finder) {
+		f.r, f.filter = r, true
+	}
+}
+
+// FindAll returns the IDs found in text, in their base32 representation.
+// Unlike a [0-9a-v]{20} regular expression, it only reports the words of 20
+// chars that are valid IDs, i.e. that are not part of a longer alphanumeric
+// word and whose last char is canonical.
+func FindAll(text []byte, opts ...FindOption) []Match {
+	var f finder
+	for _, opt := range opts {
+		opt(&f)
+	}
+	var matches []Match
+	for i := 0; i <= len(text)-encodedLen; {
+		if dec[text[i]] == 0xFF {
+			i++
+			continue
+		}
+		// Find the end of the run of base32 chars starting at i
+		j := i + 1
+		for j < len(text) && dec[text[j]] != 0xFF {
+			j++
+		}
+		if j-i == encodedLen && (i == 0 || !isAlnum(text[i-1])) && (j == len(text) || !isAlnum(text[j])) {
+			var id ID
+			if decode(&id, text[i:j]) && (!f.filter || f.r.Contains(id)) {
+				matches = append(matches, Match{ID: id, Start: i, End: j})
+			}
+		}
+		i = j
+	}
+	return matches
+}
+
+// ReplaceAll returns a copy of text with the IDs found by FindAll replaced
+// by the result of repl, i.e. to redact them.
+func ReplaceAll(text []byte, repl func(id ID) []byte, opts ...FindOption) []byte {
+	matches := FindAll(text, opts...)
+	if len(matches) == 0 {
+		return append([]byte(nil), text...)
+	}
+	out := make([]byte, 0, len(text))
+	last := 0
+	for _, m := range matches {
+		out = append(out, text[last:m.Start]...)
+		out = append(out, repl(m.ID)...)
+		last = m.End
+	}
+	return append(out, text[last:]...)
+}
+
+// isAlnum returns true if c is an ASCII letter or digit.
+func isAlnum(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
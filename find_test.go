@@ -0,0 +1,70 @@
+package xid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindAll(t *testing.T) {
+	id := testIDs[0].id
+	tests := []struct {
+		text string
+		want []Match
+	}{
+		{"", nil},
+		{"9m4e2mr0ui3e8a215n4g", []Match{{id, 0, 20}}},
+		{"req=9m4e2mr0ui3e8a215n4g, parent=9m4e2mr0ui3e8a215n4g.", []Match{{id, 4, 24}, {id, 33, 53}}},
+		{`{"id":"9m4e2mr0ui3e8a215n4g"}`, []Match{{id, 7, 27}}},
+		{"user_9m4e2mr0ui3e8a215n4g", []Match{{id, 5, 25}}},
+		// Not canonical
+		{"9m4e2mr0ui3e8a215n4h", nil},
+		// Part of longer words
+		{"19m4e2mr0ui3e8a215n4g", nil},
+		{"9m4e2mr0ui3e8a215n4g0", nil},
+		{"X9m4e2mr0ui3e8a215n4g", nil},
+		{"9m4e2mr0ui3e8a215n4gz", nil},
+		{"9M4E2MR0UI3E8A215N4G", nil},
+		// Too short
+		{"9m4e2mr0ui3e8a215n4", nil},
+	}
+	for _, tt := range tests {
+		if got := FindAll([]byte(tt.text)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindAll(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFindAllInRange(t *testing.T) {
+	text := []byte("9m4e2mr0ui3e8a215n4g 00000000000000000000 vvvvvvvvvvvvvvvvvvvg")
+	if got := FindAll(text); len(got) != 3 {
+		t.Errorf("FindAll() found %d ids, want 3", len(got))
+	}
+	r := NewIDRange(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	want := []Match{{testIDs[0].id, 0, 20}}
+	if got := FindAll(text, FindInRange(r)); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAll() = %v, want %v", got, want)
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	text := []byte("a 9m4e2mr0ui3e8a215n4g b 9m4e2mr0ui3e8a215n4h c 9m4e2mr0ui3e8a215n4g")
+	got := ReplaceAll(text, func(id ID) []byte {
+		return []byte(strings.ToUpper(id.String()[:4]))
+	})
+	if want := "a 9M4E b 9m4e2mr0ui3e8a215n4h c 9M4E"; string(got) != want {
+		t.Errorf("ReplaceAll() = %q, want %q", got, want)
+	}
+	if got := ReplaceAll([]byte("none"), nil); string(got) != "none" {
+		t.Errorf("ReplaceAll() = %q, want %q", got, "none")
+	}
+}
+
+func BenchmarkFindAll(b *testing.B) {
+	text := []byte(strings.Repeat("2011-03-22T17:50:19Z INF request id=9m4e2mr0ui3e8a215n4g status=200 took=1.2ms\n", 100))
+	b.SetBytes(int64(len(text)))
+	for i := 0; i < b.N; i++ {
+		FindAll(text)
+	}
+}
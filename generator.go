@@ -0,0 +1,594 @@
+package xid
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// defaultGenerator holds the *Generator used by the package level New*
+	// functions.
+	defaultGenerator atomic.Value
+
+	// defaultGeneratorMu serializes the replacements of defaultGenerator.
+	defaultGeneratorMu sync.Mutex
+)
+
+func init() {
+	defaultGenerator.Store(NewGenerator())
+}
+
+// DefaultGenerator returns the generator used by the package level New*
+// functions.
+func DefaultGenerator() *Generator {
+	return defaultGenerator.Load().(*Generator)
+}
+
+// SetDefaultGenerator replaces the generator used by the package level New*
+// functions and returns the previous one. It allows to control the ids
+// generated by code calling New, for instance to inject a ManualClock in
+// tests.
+func SetDefaultGenerator(g *Generator) (prev *Generator) {
+	defaultGeneratorMu.Lock()
+	defer defaultGeneratorMu.Unlock()
+	prev = DefaultGenerator()
+	defaultGenerator.Store(g)
+	return prev
+}
+
+// Generator generates IDs using its own machine id, process id and counter.
+// It is safe for concurrent use. The package level New* functions use a
+// default generator configured from the host.
+type Generator struct {
+	// machineID is the machine identifier part of generated ids.
+	machineID [3]byte
+
+	// pid is the process identifier part of generated ids.
+	pid uint16
+
+	// counter is atomically incremented when generating a new ID. It's used
+	// as the counter part of an id.
+	counter uint32
+
+	// clock provides the time of ids generated with New.
+	clock Clock
+
+	// rollback is the policy applied when the clock goes backwards.
+	rollback RollbackPolicy
+
+	// monotonic makes the generator serialize generations so each id is
+	// strictly greater than the previous one.
+	monotonic bool
+
+	// exhaustion is the policy applied when the counter is exhausted.
+	exhaustion ExhaustionPolicy
+
+	// shards caches the *counterBlock of sharded generators, nil otherwise.
+	shards *sync.Pool
+
+	// blockSize is the number of counter values reserved at once by the
+	// shards of sharded generators.
+	blockSize uint32
+
+	// state persists the counter of the generator, if set.
+	state *StateFile
+
+	// mu protects the tracking state below.
+	mu sync.Mutex
+
+	// lastClock is the latest time, in seconds, read from the clock. It's
+	// only maintained when a rollback policy is set.
+	lastClock int64
+
+	// started, last and lastCounter hold whether an id was generated and the
+	// timestamp and counter of the last generated id. They are only
+	// maintained by monotonic generators.
+	started     bool
+	last        uint32
+	lastCounter uint32
+
+	// seq is the number of counter values reserved by the generator, and
+	// starts holds the value of seq when the first id of each tracked second
+	// was reserved. As the counter wraps, a second is exhausted once the
+	// generator reserved counterSpace values since its start, whatever the
+	// seconds they were reserved for. They are only maintained when an
+	// exhaustion policy is set.
+	seq    uint64
+	starts map[uint32]uint64
+
+	// forgotten, forgottenMin, forgottenMax and forgottenSeq describe the
+	// seconds evicted from starts to bound its size: the seconds from
+	// forgottenMin to forgottenMax are assumed to have started at
+	// forgottenSeq, the earliest start of the evicted seconds.
+	forgotten    bool
+	forgottenMin uint32
+	forgottenMax uint32
+	forgottenSeq uint64
+}
+
+// RollbackPolicy defines how a generator reacts when its clock goes backwards,
+// for instance when NTP steps the wall clock.
+type RollbackPolicy int
+
+const (
+	// RollbackIgnore uses the time read from the clock as is, even if it's
+	// earlier than the time of previously generated ids. It's the default.
+	RollbackIgnore RollbackPolicy = iota
+
+	// RollbackReuse keeps using the latest time seen until the clock catches
+	// up with it.
+	RollbackReuse
+
+	// RollbackWait blocks until the clock catches up with the latest time
+	// seen.
+	RollbackWait
+
+	// RollbackError makes the generation fail with ErrClockRollback until the
+	// clock catches up with the latest time seen.
+	RollbackError
+)
+
+// ExhaustionPolicy defines how a generator reacts when the 3-byte counter
+// space is exhausted within a second, i.e. when generating an id would reuse
+// a counter value already used in the same second.
+type ExhaustionPolicy int
+
+const (
+	// ExhaustionIgnore doesn't track the usage of the counter: once exhausted,
+	// the counter wraps and generates duplicate ids. It's the default.
+	ExhaustionIgnore ExhaustionPolicy = iota
+
+	// ExhaustionWait blocks until the next second of the generator clock.
+	ExhaustionWait
+
+	// ExhaustionError makes the generation fail with ErrCounterExhausted until
+	// the next second.
+	ExhaustionError
+
+	// ExhaustionPanic panics with ErrCounterExhausted.
+	ExhaustionPanic
+)
+
+const (
+	// counterMask masks the 3 bytes of a counter value.
+	counterMask = 0xffffff
+
+	// counterSpace is the number of distinct counter values.
+	counterSpace = counterMask + 1
+
+	// pollInterval is the maximum time the generator sleeps before reading
+	// its clock again when waiting for it.
+	pollInterval = 100 * time.Millisecond
+
+	// maxTrackedSecs is the maximum number of seconds whose counter usage is
+	// tracked individually by generators with an exhaustion policy.
+	maxTrackedSecs = 1024
+)
+
+// errExhaustionWait is returned by reserve when the caller should wait for
+// the next second.
+const errExhaustionWait strErr = "xid: wait for next second"
+
+// Option configures a Generator.
+type Option func(g *Generator)
+
+// WithMachineID sets the 3-byte machine identifier used by the generator.
+func WithMachineID(machine [3]byte) Option {
+	return func(g *Generator) {
+		g.machineID = machine
+	}
+}
+
+// WithPid sets the process identifier used by the generator.
+func WithPid(pid uint16) Option {
+	return func(g *Generator) {
+		g.pid = pid
+	}
+}
+
+// WithCounter sets the counter value of the next generated ID. Only the lower
+// 24 bits are used.
+func WithCounter(counter uint32) Option {
+	return func(g *Generator) {
+		g.counter = counter - 1
+	}
+}
+
+// WithClock sets the clock used by the generator to read the current time.
+func WithClock(c Clock) Option {
+	return func(g *Generator) {
+		g.clock = c
+	}
+}
+
+// WithRollbackPolicy sets the policy applied when the generator clock goes
+// backwards. Only ids generated from the clock (New and NewChecked) are
+// concerned, times passed to NewWithTime are used as is.
+func WithRollbackPolicy(p RollbackPolicy) Option {
+	return func(g *Generator) {
+		g.rollback = p
+	}
+}
+
+// WithMonotonic makes the generator return strictly increasing ids: every id
+// compares greater than the ids previously returned by the generator, even
+// when generated concurrently or with an earlier time. When the time is
+// earlier than the one of the last id, the last timestamp is reused. When the
+// counter wraps within a second, the timestamp is moved to the next second,
+// or a *TimeRangeError is returned if the second is MaxTime.
+//
+// Monotonic generators serialize generations with a lock, so they are slower
+// under contention than the default lock-free generator.
+func WithMonotonic() Option {
+	return func(g *Generator) {
+		g.monotonic = true
+	}
+}
+
+// WithExhaustionPolicy sets the policy applied when the counter is exhausted
+// within a second. Setting a policy other than ExhaustionIgnore makes the
+// generator track the usage of the counter, serializing generations with a
+// lock.
+//
+// The usage is tracked for every second ids are generated for, including the
+// times passed to NewWithTime: as the counter is shared by all seconds, a
+// second is exhausted once 16,777,216 ids were generated since its first id,
+// whatever their time. The usage of the 1024 most recent seconds is tracked
+// individually, older seconds are conservatively considered as exhausted
+// once the counter went through a full cycle since the oldest of them.
+func WithExhaustionPolicy(p ExhaustionPolicy) Option {
+	return func(g *Generator) {
+		g.exhaustion = p
+	}
+}
+
+// NewGenerator returns a new generator configured with opts. The machine id
+// and process id default to the ones of the host, the counter starts with a
+// random value and the time is read from SystemClock.
+func NewGenerator(opts ...Option) *Generator {
+	g := &Generator{
+		pid:     uint16(pid),
+		counter: randInt(),
+		clock:   SystemClock,
+	}
+	copy(g.machineID[:], machineID)
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.state != nil {
+		g.restoreState()
+	}
+	return g
+}
+
+// New generates a unique ID using the current time of the generator clock.
+// It panics if the generator fails to generate an ID: with a rollback or
+// exhaustion policy returning errors, when a monotonic generator wraps the
+// counter of MaxTime, or when a state file checkpoint fails. Use NewChecked
+// to handle the error.
+func (g *Generator) New() ID {
+	id, err := g.NewChecked()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NewChecked generates a unique ID using the current time of the generator
+// clock, applying the generator rollback and exhaustion policies. It returns a
+// *TimeRangeError if the clock is outside of the [MinTime, MaxTime] range.
+func (g *Generator) NewChecked() (ID, error) {
+	secs, i, err := g.reserveNow(1)
+	if err != nil {
+		return nilID, err
+	}
+	return g.id(secs, i), nil
+}
+
+// NewWithTime generates a unique ID with the passed in time. Times outside of
+// the [MinTime, MaxTime] range are truncated to 32 bits. It panics if the
+// generator fails to generate an ID: with an exhaustion policy returning
+// errors, when a monotonic generator wraps the counter of MaxTime, or when a
+// state file checkpoint fails. Use NewWithTimeChecked to handle the error.
+func (g *Generator) NewWithTime(t time.Time) ID {
+	secs, i, err := g.reserve(uint32(t.Unix()), 1)
+	if err == errExhaustionWait {
+		err = ErrCounterExhausted
+	}
+	if err != nil {
+		panic(err)
+	}
+	return g.id(secs, i)
+}
+
+// NewWithTimeChecked generates a unique ID with the passed in time, applying
+// the generator exhaustion policy. As the time is imposed, ExhaustionWait
+// behaves like ExhaustionError. It returns a *TimeRangeError if t is outside
+// of the [MinTime, MaxTime] range.
+func (g *Generator) NewWithTimeChecked(t time.Time) (ID, error) {
+	if err := checkTime(t); err != nil {
+		return nilID, err
+	}
+	secs, i, err := g.reserve(uint32(t.Unix()), 1)
+	if err == errExhaustionWait {
+		err = ErrCounterExhausted
+	}
+	if err != nil {
+		return nilID, err
+	}
+	return g.id(secs, i), nil
+}
+
+// NewBatch generates n unique IDs sharing the current time of the generator
+// clock. See Fill, including for the uniqueness guarantees across batches.
+func (g *Generator) NewBatch(n int) ([]ID, error) {
+	ids := make([]ID, n)
+	if err := g.Fill(ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Fill fills ids with unique IDs sharing the current time of the generator
+// clock. The counter values are reserved at once, so it's cheaper than
+// calling New for each id. It returns ErrCounterExhausted if ids is larger
+// than the number of ids that can be generated within a second, and applies
+// the generator rollback and exhaustion policies otherwise.
+//
+// Like for New, the limit of 16,777,216 ids per second is only enforced
+// across calls when an exhaustion policy is set (see WithExhaustionPolicy).
+// With the default ExhaustionIgnore policy, batches generated within the same
+// second and totalling more ids than this limit wrap the counter and contain
+// duplicates.
+func (g *Generator) Fill(ids []ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if len(ids) > counterSpace {
+		return ErrCounterExhausted
+	}
+	secs, i, err := g.reserveNow(uint32(len(ids)))
+	if err != nil {
+		return err
+	}
+	for k := range ids {
+		ids[k] = g.id(secs, i+uint32(k))
+	}
+	return nil
+}
+
+// Headroom returns the number of ids the generator can still generate in the
+// current second of its clock before exhausting its counter. The usage of the
+// counter is only tracked when an exhaustion policy is set, Headroom always
+// returns the full counter capacity otherwise.
+func (g *Generator) Headroom() int {
+	if g.exhaustion == ExhaustionIgnore {
+		return counterSpace
+	}
+	secs := uint32(g.clock.Now().Unix())
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if used := g.usage(secs); used < counterSpace {
+		return counterSpace - int(used)
+	}
+	return 0
+}
+
+// reserveNow reserves n counter values for the current time of the clock,
+// applying the rollback policy and waiting for the next second if the counter
+// is exhausted and the policy is ExhaustionWait. It returns the timestamp and
+// the first reserved counter value.
+func (g *Generator) reserveNow(n uint32) (secs, i uint32, err error) {
+	for {
+		t, err := g.now()
+		if err == nil {
+			err = checkTime(t)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		secs, i, err = g.reserve(uint32(t.Unix()), n)
+		if err != errExhaustionWait {
+			return secs, i, err
+		}
+		g.sleepUntil(time.Unix(int64(secs)+1, 0))
+	}
+}
+
+// now reads the current time from the clock and applies the rollback policy.
+func (g *Generator) now() (time.Time, error) {
+	t := g.clock.Now()
+	if g.rollback == RollbackIgnore {
+		return t, nil
+	}
+	for {
+		g.mu.Lock()
+		secs, last := t.Unix(), g.lastClock
+		if secs >= last {
+			g.lastClock = secs
+		}
+		g.mu.Unlock()
+		if secs >= last {
+			return t, nil
+		}
+		switch g.rollback {
+		case RollbackReuse:
+			return time.Unix(last, 0), nil
+		case RollbackError:
+			return t, ErrClockRollback
+		}
+		g.sleepUntil(time.Unix(last, 0))
+		t = g.clock.Now()
+	}
+}
+
+// checkTime returns a *TimeRangeError if t can't be represented in an id.
+func checkTime(t time.Time) error {
+	if secs := t.Unix(); secs < 0 || secs > math.MaxUint32 {
+		return &TimeRangeError{Time: t}
+	}
+	return nil
+}
+
+// sleepUntil sleeps until the generator clock reaches t.
+func (g *Generator) sleepUntil(t time.Time) {
+	for {
+		wait := t.Sub(g.clock.Now())
+		if wait <= 0 {
+			return
+		}
+		if wait > pollInterval {
+			wait = pollInterval
+		}
+		time.Sleep(wait)
+	}
+}
+
+// reserve reserves n consecutive counter values for the timestamp secs. It
+// returns the timestamp to use, which may differ from secs for monotonic
+// generators, and the first reserved counter value.
+func (g *Generator) reserve(secs, n uint32) (uint32, uint32, error) {
+	if n > counterSpace {
+		return 0, 0, ErrCounterExhausted
+	}
+	if !g.monotonic && g.exhaustion == ExhaustionIgnore {
+		if g.shards != nil && n == 1 {
+			i, err := g.reserveSharded(secs)
+			return secs, i, err
+		}
+		i, err := g.advance(n)
+		return secs, i - (n - 1), err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.monotonic && g.started && secs < g.last {
+		secs = g.last
+	}
+	if g.exhaustion != ExhaustionIgnore && g.usage(secs)+uint64(n) > counterSpace {
+		switch g.exhaustion {
+		case ExhaustionWait:
+			return secs, 0, errExhaustionWait
+		case ExhaustionPanic:
+			panic(ErrCounterExhausted)
+		}
+		return 0, 0, ErrCounterExhausted
+	}
+	i, err := g.advance(n)
+	if err != nil {
+		return 0, 0, err
+	}
+	i -= n - 1
+	if g.monotonic {
+		if c := i & counterMask; c+n-1 > counterMask {
+			// The reserved range wraps, skip to the start of the counter
+			// space so counters remain increasing within the range.
+			if i, err = g.advance(counterSpace - c); err != nil {
+				return 0, 0, err
+			}
+			i -= n - 1
+		}
+		if g.started && secs == g.last && i&counterMask <= g.lastCounter {
+			// The counter wrapped within the second
+			if secs == math.MaxUint32 {
+				return 0, 0, &TimeRangeError{Time: time.Unix(int64(secs)+1, 0)}
+			}
+			secs++
+		}
+		g.started, g.last, g.lastCounter = true, secs, (i+n-1)&counterMask
+	}
+	if g.exhaustion != ExhaustionIgnore {
+		g.track(secs, n)
+	}
+	return secs, i, nil
+}
+
+// usage returns the number of counter values reserved since the first id of
+// the second secs, which can't be reserved again for secs once it reaches
+// counterSpace. It must be called with g.mu held.
+func (g *Generator) usage(secs uint32) uint64 {
+	if start, found := g.starts[secs]; found {
+		return g.seq - start
+	}
+	if g.forgotten && g.forgottenMin <= secs && secs <= g.forgottenMax {
+		return g.seq - g.forgottenSeq
+	}
+	return 0
+}
+
+// track records the reservation of n counter values for the second secs. It
+// must be called with g.mu held.
+func (g *Generator) track(secs, n uint32) {
+	if _, found := g.starts[secs]; !found {
+		if g.starts == nil {
+			g.starts = make(map[uint32]uint64)
+		}
+		// A forgotten second keeps its assumed start
+		g.starts[secs] = g.seq - g.usage(secs)
+		if len(g.starts) > maxTrackedSecs {
+			g.forget()
+		}
+	}
+	g.seq += uint64(n)
+}
+
+// forget evicts the second with the earliest start from starts. The evicted
+// seconds are then tracked as a range, conservatively assumed to have all
+// started with the earliest evicted one. It must be called with g.mu held.
+func (g *Generator) forget() {
+	var secs uint32
+	start := g.seq + 1
+	for s, st := range g.starts {
+		if st < start {
+			secs, start = s, st
+		}
+	}
+	delete(g.starts, secs)
+	if !g.forgotten {
+		g.forgotten, g.forgottenMin, g.forgottenMax, g.forgottenSeq = true, secs, secs, start
+		return
+	}
+	if secs < g.forgottenMin {
+		g.forgottenMin = secs
+	}
+	if secs > g.forgottenMax {
+		g.forgottenMax = secs
+	}
+	if start < g.forgottenSeq {
+		g.forgottenSeq = start
+	}
+}
+
+// advance atomically adds n to the counter and returns its new value. If the
+// generator has a state file, it makes sure the new value is covered by a
+// checkpoint before returning it.
+func (g *Generator) advance(n uint32) (uint32, error) {
+	i := atomic.AddUint32(&g.counter, n)
+	if g.state != nil && int32(i-atomic.LoadUint32(&g.state.limit)) >= 0 {
+		return i, g.checkpoint(i)
+	}
+	return i, nil
+}
+
+// id builds an id from the timestamp secs, the counter i and the generator
+// machine id and pid.
+func (g *Generator) id(secs, i uint32) ID {
+	var id ID
+	// Timestamp, 4 bytes, big endian
+	binary.BigEndian.PutUint32(id[:], secs)
+	// Machine ID, 3 bytes
+	id[4] = g.machineID[0]
+	id[5] = g.machineID[1]
+	id[6] = g.machineID[2]
+	// Pid, 2 bytes, specs don't specify endianness, but we use big endian.
+	id[7] = byte(g.pid >> 8)
+	id[8] = byte(g.pid)
+	// Increment, 3 bytes, big endian
+	id[9] = byte(i >> 16)
+	id[10] = byte(i >> 8)
+	id[11] = byte(i)
+	return id
+}
@@ -0,0 +1,392 @@
+package xid
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewGenerator(t *testing.T) {
+	g := NewGenerator(
+		WithMachineID([3]byte{0xaa, 0xbb, 0xcc}),
+		WithPid(0xddee),
+		WithCounter(0x123456),
+	)
+	now := time.Unix(1300816219, 0)
+	id := g.NewWithTime(now)
+	if got, want := id.Time(), now; got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+	if got, want := id.Machine(), []byte{0xaa, 0xbb, 0xcc}; !bytes.Equal(got, want) {
+		t.Errorf("Machine() = %v, want %v", got, want)
+	}
+	if got, want := id.Pid(), uint16(0xddee); got != want {
+		t.Errorf("Pid() = %v, want %v", got, want)
+	}
+	if got, want := id.Counter(), int32(0x123456); got != want {
+		t.Errorf("Counter() = %v, want %v", got, want)
+	}
+	if got, want := g.NewWithTime(now).Counter(), int32(0x123457); got != want {
+		t.Errorf("Counter() = %v, want %v", got, want)
+	}
+}
+
+func TestNewGeneratorDefaults(t *testing.T) {
+	id := NewGenerator().New()
+	if got, want := id.Machine(), machineID; !bytes.Equal(got, want) {
+		t.Errorf("Machine() = %v, want %v", got, want)
+	}
+	if got, want := id.Pid(), uint16(pid); got != want {
+		t.Errorf("Pid() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorIndependence(t *testing.T) {
+	g1 := NewGenerator(WithCounter(0))
+	g2 := NewGenerator(WithCounter(0))
+	g1.New()
+	g1.New()
+	if got, want := g2.New().Counter(), int32(0); got != want {
+		t.Errorf("Counter() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorCounterWrap(t *testing.T) {
+	g := NewGenerator(WithCounter(0xffffff))
+	now := time.Now()
+	if got, want := g.NewWithTime(now).Counter(), int32(0xffffff); got != want {
+		t.Errorf("Counter() = %v, want %v", got, want)
+	}
+	if got, want := g.NewWithTime(now).Counter(), int32(0); got != want {
+		t.Errorf("Counter() = %v, want %v", got, want)
+	}
+}
+
+// assertConcurrentUnique generates ids with g from concurrent workers and
+// fails the test if any of them is duplicated. It returns the ids of each
+// worker, in generation order.
+func assertConcurrentUnique(t *testing.T, g *Generator) [][]ID {
+	t.Helper()
+	const workers, perWorker = 8, 1000
+	batches := make([][]ID, workers)
+	var wg sync.WaitGroup
+	for w := range batches {
+		batches[w] = make([]ID, perWorker)
+		wg.Add(1)
+		go func(ids []ID) {
+			defer wg.Done()
+			for i := range ids {
+				ids[i] = g.New()
+			}
+		}(batches[w])
+	}
+	wg.Wait()
+	seen := make(map[ID]struct{}, workers*perWorker)
+	for _, ids := range batches {
+		for _, id := range ids {
+			seen[id] = struct{}{}
+		}
+	}
+	if got, want := len(seen), workers*perWorker; got != want {
+		t.Errorf("generated %d unique ids, want %d", got, want)
+	}
+	return batches
+}
+
+func TestGeneratorConcurrentUnique(t *testing.T) {
+	assertConcurrentUnique(t, NewGenerator())
+}
+
+func TestMonotonicCounterWrap(t *testing.T) {
+	now := time.Unix(1300816219, 0)
+	g := NewGenerator(WithMonotonic(), WithCounter(0xfffffe))
+	prev := g.NewWithTime(now)
+	for i := 0; i < 3; i++ {
+		id := g.NewWithTime(now)
+		if prev.Compare(id) >= 0 {
+			t.Fatalf("%v is not greater than %v", id, prev)
+		}
+		prev = id
+	}
+	if got, want := prev.Time(), now.Add(time.Second); got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestMonotonicCounterWrapMaxTime(t *testing.T) {
+	g := NewGenerator(WithMonotonic(), WithCounter(0xffffff))
+	prev, err := g.NewWithTimeChecked(MaxTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := g.NewWithTimeChecked(MaxTime)
+	if _, ok := err.(*TimeRangeError); !ok {
+		t.Errorf("NewWithTimeChecked() = %v, %v, want a *TimeRangeError after %v", id, err, prev)
+	}
+}
+
+func TestMonotonicEarlierTime(t *testing.T) {
+	now := time.Unix(1300816219, 0)
+	g := NewGenerator(WithMonotonic())
+	prev := g.NewWithTime(now)
+	id := g.NewWithTime(now.Add(-time.Minute))
+	if prev.Compare(id) >= 0 {
+		t.Errorf("%v is not greater than %v", id, prev)
+	}
+	if got, want := id.Time(), now; got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestMonotonicSecondBoundary(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	g := NewGenerator(WithMonotonic(), WithClock(c), WithCounter(0xffffff))
+	prev := g.New()
+	c.Advance(time.Second)
+	if id := g.New(); prev.Compare(id) >= 0 {
+		t.Errorf("%v is not greater than %v", id, prev)
+	}
+}
+
+func TestMonotonicConcurrent(t *testing.T) {
+	g := NewGenerator(WithMonotonic(), WithCounter(0xfff000))
+	for _, ids := range assertConcurrentUnique(t, g) {
+		for i := 1; i < len(ids); i++ {
+			if ids[i-1].Compare(ids[i]) >= 0 {
+				t.Fatalf("%v is not greater than %v", ids[i], ids[i-1])
+			}
+		}
+	}
+}
+
+// exhaust marks all but n counter values as used for the current second of g.
+func exhaust(g *Generator, n uint32) {
+	g.mu.Lock()
+	secs := uint32(g.clock.Now().Unix())
+	g.track(secs, 0)
+	g.seq = g.starts[secs] + uint64(counterSpace-n)
+	g.mu.Unlock()
+}
+
+func TestExhaustionIgnore(t *testing.T) {
+	g := NewGenerator()
+	exhaust(g, 0)
+	if _, err := g.NewChecked(); err != nil {
+		t.Errorf("NewChecked() err=%v, want nil", err)
+	}
+	if got, want := g.Headroom(), counterSpace; got != want {
+		t.Errorf("Headroom() = %v, want %v", got, want)
+	}
+}
+
+func TestExhaustionError(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	g := NewGenerator(WithClock(c), WithExhaustionPolicy(ExhaustionError))
+	if got, want := g.Headroom(), counterSpace; got != want {
+		t.Errorf("Headroom() = %v, want %v", got, want)
+	}
+	g.New()
+	if got, want := g.Headroom(), counterSpace-1; got != want {
+		t.Errorf("Headroom() = %v, want %v", got, want)
+	}
+	exhaust(g, 1)
+	if _, err := g.NewChecked(); err != nil {
+		t.Fatalf("NewChecked() err=%v, want nil", err)
+	}
+	if got, want := g.Headroom(), 0; got != want {
+		t.Errorf("Headroom() = %v, want %v", got, want)
+	}
+	if _, err := g.NewChecked(); err != ErrCounterExhausted {
+		t.Errorf("NewChecked() err=%v, want %v", err, ErrCounterExhausted)
+	}
+	if _, err := g.NewWithTimeChecked(c.Now()); err != ErrCounterExhausted {
+		t.Errorf("NewWithTimeChecked() err=%v, want %v", err, ErrCounterExhausted)
+	}
+	c.Advance(time.Second)
+	if _, err := g.NewChecked(); err != nil {
+		t.Errorf("NewChecked() err=%v, want nil", err)
+	}
+	if got, want := g.Headroom(), counterSpace-1; got != want {
+		t.Errorf("Headroom() = %v, want %v", got, want)
+	}
+}
+
+func TestExhaustionPanic(t *testing.T) {
+	g := NewGenerator(WithExhaustionPolicy(ExhaustionPanic))
+	exhaust(g, 0)
+	defer func() {
+		if r := recover(); r != ErrCounterExhausted {
+			t.Errorf("NewChecked() panic=%v, want %v", r, ErrCounterExhausted)
+		}
+	}()
+	_, _ = g.NewChecked()
+}
+
+func TestExhaustionWait(t *testing.T) {
+	start := time.Unix(1300816219, 0)
+	c := NewManualClock(start)
+	g := NewGenerator(WithClock(c), WithExhaustionPolicy(ExhaustionWait))
+	exhaust(g, 0)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		c.Advance(time.Second)
+	}()
+	if got, want := g.New().Time(), start.Add(time.Second); got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+// skip simulates the reservation of n counter values for other seconds.
+func skip(g *Generator, n uint32) {
+	g.mu.Lock()
+	g.advance(n)
+	g.seq += uint64(n)
+	g.mu.Unlock()
+}
+
+func TestExhaustionAlternatingSeconds(t *testing.T) {
+	t1, t2 := time.Unix(1300816219, 0), time.Unix(1300816220, 0)
+	g := NewGenerator(WithCounter(0), WithExhaustionPolicy(ExhaustionError))
+	if _, err := g.NewWithTimeChecked(t1); err != nil {
+		t.Fatal(err)
+	}
+	skip(g, counterSpace-2)
+	if _, err := g.NewWithTimeChecked(t1); err != nil {
+		t.Fatalf("NewWithTimeChecked() err=%v, want nil", err)
+	}
+	// The counter wraps, the next id of t1 would be a duplicate
+	if _, err := g.NewWithTimeChecked(t2); err != nil {
+		t.Fatalf("NewWithTimeChecked() err=%v, want nil", err)
+	}
+	if id, err := g.NewWithTimeChecked(t1); err != ErrCounterExhausted {
+		t.Errorf("NewWithTimeChecked() = %v, %v, want %v", id, err, ErrCounterExhausted)
+	}
+	if _, err := g.NewWithTimeChecked(t2); err != nil {
+		t.Errorf("NewWithTimeChecked() err=%v, want nil", err)
+	}
+}
+
+func TestExhaustionForgottenSeconds(t *testing.T) {
+	start := time.Unix(1300816219, 0)
+	g := NewGenerator(WithExhaustionPolicy(ExhaustionError))
+	for k := 0; k < maxTrackedSecs+10; k++ {
+		if _, err := g.NewWithTimeChecked(start.Add(time.Duration(k) * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := len(g.starts), maxTrackedSecs; got != want {
+		t.Errorf("tracked %d seconds, want %d", got, want)
+	}
+	skip(g, counterSpace)
+	if _, err := g.NewWithTimeChecked(start); err != ErrCounterExhausted {
+		t.Errorf("NewWithTimeChecked() err=%v, want %v", err, ErrCounterExhausted)
+	}
+	if _, err := g.NewWithTimeChecked(start.Add(time.Hour)); err != nil {
+		t.Errorf("NewWithTimeChecked() err=%v, want nil", err)
+	}
+}
+
+func TestGeneratorFill(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	g := NewGenerator(WithClock(c), WithCounter(0xfffffe))
+	ids := make([]ID, 4)
+	if err := g.Fill(ids); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range []int32{0xfffffe, 0xffffff, 0, 1} {
+		if got := ids[k].Counter(); got != want {
+			t.Errorf("ids[%d].Counter() = %v, want %v", k, got, want)
+		}
+		if got, want := ids[k].Time(), c.Now(); got != want {
+			t.Errorf("ids[%d].Time() = %v, want %v", k, got, want)
+		}
+	}
+	if got, want := g.New().Counter(), int32(2); got != want {
+		t.Errorf("Counter() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorFillMonotonic(t *testing.T) {
+	g := NewGenerator(WithMonotonic(), WithCounter(0xfffffe))
+	prev := g.New()
+	ids, err := g.NewBatch(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if prev.Compare(id) >= 0 {
+			t.Fatalf("%v is not greater than %v", id, prev)
+		}
+		prev = id
+	}
+	if next := g.New(); prev.Compare(next) >= 0 {
+		t.Errorf("%v is not greater than %v", next, prev)
+	}
+}
+
+func TestGeneratorFillExhausted(t *testing.T) {
+	g := NewGenerator(WithExhaustionPolicy(ExhaustionError))
+	if err := g.Fill(make([]ID, counterSpace+1)); err != ErrCounterExhausted {
+		t.Errorf("Fill() err=%v, want %v", err, ErrCounterExhausted)
+	}
+	exhaust(g, 2)
+	if err := g.Fill(make([]ID, 3)); err != ErrCounterExhausted {
+		t.Errorf("Fill() err=%v, want %v", err, ErrCounterExhausted)
+	}
+	if err := g.Fill(make([]ID, 2)); err != nil {
+		t.Errorf("Fill() err=%v, want nil", err)
+	}
+	if got, want := g.Headroom(), 0; got != want {
+		t.Errorf("Headroom() = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratorFillAcrossBatches(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	// Without an exhaustion policy, the usage of the counter isn't tracked
+	// across batches and the counter wraps to generate duplicates.
+	g := NewGenerator(WithClock(c), WithCounter(0))
+	first, err := g.NewBatch(1)
+	if err != nil {
+		t.Fatalf("NewBatch() err=%v, want nil", err)
+	}
+	skip(g, counterSpace-1)
+	if ids, err := g.NewBatch(1); err != nil || ids[0] != first[0] {
+		t.Errorf("NewBatch() = %v, %v, want %v", ids, err, first)
+	}
+	// With an exhaustion policy, batches share the limit of the second.
+	g = NewGenerator(WithClock(c), WithExhaustionPolicy(ExhaustionError))
+	if _, err := g.NewBatch(counterSpace / 2); err != nil {
+		t.Fatalf("NewBatch() err=%v, want nil", err)
+	}
+	if _, err := g.NewBatch(counterSpace/2 + 1); err != ErrCounterExhausted {
+		t.Errorf("NewBatch() err=%v, want %v", err, ErrCounterExhausted)
+	}
+}
+
+func TestNewBatchEmpty(t *testing.T) {
+	ids, err := NewBatch(0)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("NewBatch(0) = %v, %v, want empty", ids, err)
+	}
+}
+
+func BenchmarkGeneratorNew(b *testing.B) {
+	g := NewGenerator()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = g.New()
+		}
+	})
+}
+
+func BenchmarkGeneratorFill(b *testing.B) {
+	g := NewGenerator()
+	b.RunParallel(func(pb *testing.PB) {
+		ids := make([]ID, 1000)
+		for pb.Next() {
+			_ = g.Fill(ids)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package xid
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// hexLen is the length of the hex representation of an id.
+const hexLen = 2 * rawLen
+
+// Hex returns the 24 chars lowercase hex representation of the id, as used by
+// MongoDB ObjectIds.
+func (id ID) Hex() string {
+	return hex.EncodeToString(id[:])
+}
+
+// FromHex reads an ID from its hex representation.
+func FromHex(s string) (ID, error) {
+	var id ID
+	if len(s) != hexLen {
+		return id, ErrInvalidID
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return nilID, ErrInvalidID
+	}
+	return id, nil
+}
+
+// ParseAny reads an ID from any of its supported string representations,
+// detected from the input:
+//
+//   - the 20 chars base32 representation (see FromString),
+//   - the 24 chars hex representation (see FromHex),
+//   - the MongoDB shell form: ObjectId("5e1a1b2c3d4e5f6a7b8c9d0e"),
+//   - the MongoDB extended JSON form: {"$oid": "5e1a1b2c3d4e5f6a7b8c9d0e"}.
+func ParseAny(s string) (ID, error) {
+	switch {
+	case len(s) == encodedLen:
+		return FromString(s)
+	case len(s) == hexLen:
+		return FromHex(s)
+	case strings.HasPrefix(s, `ObjectId("`) && strings.HasSuffix(s, `")`):
+		return FromHex(s[len(`ObjectId("`) : len(s)-len(`")`)])
+	case strings.HasPrefix(s, "{"):
+		var v struct {
+			OID *string `json:"$oid"`
+		}
+		if err := json.Unmarshal([]byte(s), &v); err != nil || v.OID == nil {
+			return nilID, ErrInvalidID
+		}
+		return FromHex(*v.OID)
+	}
+	return nilID, ErrInvalidID
+}
@@ -0,0 +1,71 @@
+package xid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIDHex(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	if got, want := id.Hex(), "4d88e15b60f486e428412dc9"; got != want {
+		t.Errorf("Hex() = %v, want %v", got, want)
+	}
+}
+
+func TestFromHex(t *testing.T) {
+	want := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, s := range []string{"4d88e15b60f486e428412dc9", "4D88E15B60F486E428412DC9"} {
+		got, err := FromHex(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("FromHex(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestFromHexInvalid(t *testing.T) {
+	for _, s := range []string{"", "4d88e15b60f486e428412d", "4d88e15b60f486e428412dcz", "4d88e15b60f486e428412dc900"} {
+		if id, err := FromHex(s); !errors.Is(err, ErrInvalidID) || !id.IsNil() {
+			t.Errorf("FromHex(%q) = %v, %v, want %v", s, id, err, ErrInvalidID)
+		}
+	}
+}
+
+func TestParseAny(t *testing.T) {
+	want := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, s := range []string{
+		"9m4e2mr0ui3e8a215n4g",
+		"4d88e15b60f486e428412dc9",
+		`ObjectId("4d88e15b60f486e428412dc9")`,
+		`{"$oid":"4d88e15b60f486e428412dc9"}`,
+		`{ "$oid": "4d88e15b60f486e428412dc9" }`,
+	} {
+		got, err := ParseAny(s)
+		if err != nil {
+			t.Errorf("ParseAny(%q) err=%v", s, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseAny(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestParseAnyInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"invalid",
+		"9M4E2MR0UI3E8A215N4G",
+		`ObjectId("9m4e2mr0ui3e8a215n4g")`,
+		`ObjectId("4d88e15b60f486e428412dc9"`,
+		`{"oid":"4d88e15b60f486e428412dc9"}`,
+		`{"$oid":1}`,
+		`{"$oid":"4d88e15b60f486e428412dc9"`,
+	} {
+		if _, err := ParseAny(s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("ParseAny(%q) err=%v, want %v", s, err, ErrInvalidID)
+		}
+	}
+}
@@ -49,10 +49,10 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash/crc32"
+	"math"
 	"os"
 	"sort"
 	"strconv"
-	"sync/atomic"
 	"time"
 )
 
@@ -71,18 +71,20 @@ const (
 )
 
 var (
-	// objectIDCounter is atomically incremented when generating a new ObjectId. It's
-	// used as the counter part of an id. This id is initialized with a random value.
-	objectIDCounter = randInt()
-
 	// machineID is generated once and used in subsequent calls to the New* functions.
 	machineID = readMachineID()
 
 	// pid stores the current process id
-	pid = os.Getpid()
+	pid = readPid()
 
 	nilID ID
 
+	// MinTime is the earliest time that can be represented in an ID.
+	MinTime = time.Unix(0, 0).UTC()
+
+	// MaxTime is the latest time that can be represented in an ID.
+	MaxTime = time.Unix(math.MaxUint32, 0).UTC()
+
 	// dec is the decoding map for base32 encoding
 	dec [256]byte
 )
@@ -94,14 +96,19 @@ func init() {
 	for i := 0; i < len(encoding); i++ {
 		dec[encoding[i]] = byte(i)
 	}
+}
 
-	// If /proc/self/cpuset exists and is not /, we can assume that we are in a
-	// form of container and use the content of cpuset xor-ed with the PID in
-	// order get a reasonable machine global unique PID.
+// readPid returns the current process id. If /proc/self/cpuset exists and is
+// not /, we can assume that we are in a form of container and use the content
+// of cpuset xor-ed with the PID in order get a reasonable machine global unique
+// PID.
+func readPid() int {
+	pid := os.Getpid()
 	b, err := os.ReadFile("/proc/self/cpuset")
 	if err == nil && len(b) > 1 {
 		pid ^= int(crc32.ChecksumIEEE(b))
 	}
+	return pid
 }
 
 // readMachineID generates a machine ID, derived from a platform-specific machine ID
@@ -119,9 +126,8 @@ func readMachineID() []byte {
 		hid, err = os.Hostname()
 	}
 	if err == nil && len(hid) != 0 {
-		hw := sha256.New()
-		hw.Write([]byte(hid))
-		copy(id, hw.Sum(nil))
+		mid := MachineIDFor(hid)
+		copy(id, mid[:])
 	} else {
 		// Fallback to rand number if machine id can't be gathered
 		if _, randErr := rand.Reader.Read(id); randErr != nil {
@@ -131,6 +137,19 @@ func readMachineID() []byte {
 	return id
 }
 
+// MachineIDFor returns the machine id derived from source, a platform machine
+// id or a hostname, the same way it is derived for the current machine: the
+// first 3 bytes of the sha256 of source.
+//
+// The source is hashed as is. On Linux, the platform machine id is the content
+// of /etc/machine-id, including its trailing newline.
+func MachineIDFor(source string) [3]byte {
+	var id [3]byte
+	sum := sha256.Sum256([]byte(source))
+	copy(id[:], sum[:])
+	return id
+}
+
 func readMachineIDFromEnv() []byte {
 	envMachineID := os.Getenv("XID_MACHINE_ID")
 	if envMachineID == "" {
@@ -161,27 +180,34 @@ func randInt() uint32 {
 
 // New generates a globally unique ID
 func New() ID {
-	return NewWithTime(time.Now())
+	return DefaultGenerator().New()
 }
 
 // NewWithTime generates a globally unique ID with the passed in time
 func NewWithTime(t time.Time) ID {
-	var id ID
-	// Timestamp, 4 bytes, big endian
-	binary.BigEndian.PutUint32(id[:], uint32(t.Unix()))
-	// Machine ID, 3 bytes
-	id[4] = machineID[0]
-	id[5] = machineID[1]
-	id[6] = machineID[2]
-	// Pid, 2 bytes, specs don't specify endianness, but we use big endian.
-	id[7] = byte(pid >> 8)
-	id[8] = byte(pid)
-	// Increment, 3 bytes, big endian
-	i := atomic.AddUint32(&objectIDCounter, 1)
-	id[9] = byte(i >> 16)
-	id[10] = byte(i >> 8)
-	id[11] = byte(i)
-	return id
+	return DefaultGenerator().NewWithTime(t)
+}
+
+// NewBatch generates n globally unique IDs sharing the current time. The
+// default generator doesn't track the usage of the counter, see
+// Generator.Fill.
+func NewBatch(n int) ([]ID, error) {
+	return DefaultGenerator().NewBatch(n)
+}
+
+// Fill fills ids with globally unique IDs sharing the current time, reserving
+// the counter values at once. It's cheaper than calling New for each id. The
+// default generator doesn't track the usage of the counter, see
+// Generator.Fill.
+func Fill(ids []ID) error {
+	return DefaultGenerator().Fill(ids)
+}
+
+// NewWithTimeChecked generates a globally unique ID with the passed in time.
+// Unlike NewWithTime, it returns a *TimeRangeError instead of truncating the
+// timestamp when t is outside of the [MinTime, MaxTime] range.
+func NewWithTimeChecked(t time.Time) (ID, error) {
+	return DefaultGenerator().NewWithTimeChecked(t)
 }
 
 // FromString reads an ID from its string representation
@@ -222,6 +248,75 @@ func (id ID) MarshalJSON() ([]byte, error) {
 	return text, nil
 }
 
+// AppendText implements encoding TextAppender interface. It appends the base32
+// representation of the id to dst, without allocating if dst has enough
+// capacity.
+func (id ID) AppendText(dst []byte) ([]byte, error) {
+	text := id.Text()
+	return append(dst, text[:]...), nil
+}
+
+// AppendJSON appends the JSON representation of the id to dst, as returned by
+// MarshalJSON, without allocating if dst has enough capacity.
+func (id ID) AppendJSON(dst []byte) ([]byte, error) {
+	if id.IsNil() {
+		return append(dst, "null"...), nil
+	}
+	text := id.Text()
+	dst = append(dst, '"')
+	dst = append(dst, text[:]...)
+	return append(dst, '"'), nil
+}
+
+// MarshalBinary implements encoding BinaryMarshaler interface, returning the
+// 12 raw bytes of the id.
+//
+// encoding/gob uses it in place of MarshalText: gob data holding ids written
+// by earlier versions, which encoded them as text, can't be decoded anymore
+// and the other way around.
+func (id ID) MarshalBinary() ([]byte, error) {
+	return id.AppendBinary(make([]byte, 0, rawLen))
+}
+
+// AppendBinary implements encoding BinaryAppender interface. It appends the 12
+// raw bytes of the id to dst.
+func (id ID) AppendBinary(dst []byte) ([]byte, error) {
+	return append(dst, id[:]...), nil
+}
+
+// UnmarshalBinary implements encoding BinaryUnmarshaler interface
+func (id *ID) UnmarshalBinary(data []byte) error {
+	i, err := FromBytes(data)
+	if err != nil {
+		return err
+	}
+	*id = i
+	return nil
+}
+
+// Text is the fixed size base32 representation of an ID. Unlike a string, it
+// can be rendered and used as a map key without heap allocation.
+type Text [encodedLen]byte
+
+// Text returns the base32 representation of the id as a Text.
+func (id ID) Text() Text {
+	var text Text
+	encode(text[:], id[:])
+	return text
+}
+
+// String returns the text as a string.
+func (t Text) String() string {
+	return string(t[:])
+}
+
+// ID reads the ID represented by the text.
+func (t Text) ID() (ID, error) {
+	var id ID
+	err := id.UnmarshalText(t[:])
+	return id, err
+}
+
 // encode by unrolling the stdlib base32 algorithm + removing all safe checks
 func encode(dst, id []byte) {
 	_ = dst[19]
@@ -252,16 +347,20 @@ func encode(dst, id []byte) {
 // UnmarshalText implements encoding/text TextUnmarshaler interface
 func (id *ID) UnmarshalText(text []byte) error {
 	if len(text) != encodedLen {
-		return ErrInvalidID
+		offset := len(text)
+		if offset > encodedLen {
+			offset = encodedLen
+		}
+		return &ParseError{Input: string(text), Offset: offset, Reason: ReasonLength}
 	}
-	for _, c := range text {
+	for i, c := range text {
 		if dec[c] == 0xFF {
-			return ErrInvalidID
+			return &ParseError{Input: string(text), Offset: i, Reason: ReasonChar}
 		}
 	}
 	if !decode(id, text) {
 		*id = nilID
-		return ErrInvalidID
+		return &ParseError{Input: string(text), Offset: encodedLen - 1, Reason: ReasonNonCanonical}
 	}
 	return nil
 }
@@ -275,9 +374,22 @@ func (id *ID) UnmarshalJSON(b []byte) error {
 	}
 	// Check the slice length to prevent panic on passing it to UnmarshalText()
 	if len(b) < 2 {
-		return ErrInvalidID
+		return &ParseError{Input: s, Offset: len(b), Reason: ReasonLength}
 	}
-	return id.UnmarshalText(b[1 : len(b)-1])
+	if b[0] != '"' {
+		return &ParseError{Input: s, Offset: 0, Reason: ReasonChar}
+	}
+	if b[len(b)-1] != '"' {
+		return &ParseError{Input: s, Offset: len(b) - 1, Reason: ReasonChar}
+	}
+	if err := id.UnmarshalText(b[1 : len(b)-1]); err != nil {
+		// Report the error relative to the JSON input
+		perr := err.(*ParseError)
+		perr.Input = s
+		perr.Offset++
+		return perr
+	}
+	return nil
 }
 
 // decode by unrolling the stdlib base32 algorithm + customized safe check.
@@ -380,7 +492,11 @@ func (id ID) Bytes() []byte {
 func FromBytes(b []byte) (ID, error) {
 	var id ID
 	if len(b) != rawLen {
-		return id, ErrInvalidID
+		offset := len(b)
+		if offset > rawLen {
+			offset = rawLen
+		}
+		return id, &ParseError{Input: string(b), Offset: offset, Reason: ReasonLength}
 	}
 	copy(id[:], b)
 	return id, nil
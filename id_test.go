@@ -2,12 +2,14 @@ package xid
 
 import (
 	"bytes"
+	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"testing/quick"
 	"time"
@@ -21,7 +23,7 @@ type IDParts struct {
 	counter   int32
 }
 
-var IDs = []IDParts{
+var testIDs = []IDParts{
 	{
 		ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9},
 		1300816219,
@@ -46,7 +48,7 @@ var IDs = []IDParts{
 }
 
 func TestIDPartsExtraction(t *testing.T) {
-	for i, v := range IDs {
+	for i, v := range testIDs {
 		t.Run(fmt.Sprintf("Test%d", i), func(t *testing.T) {
 			if got, want := v.id.Time(), time.Unix(v.timestamp, 0); got != want {
 				t.Errorf("Time() = %v, want %v", got, want)
@@ -118,6 +120,55 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithTimeChecked(t *testing.T) {
+	for _, tt := range []struct {
+		t       time.Time
+		wantErr bool
+	}{
+		{MinTime, false},
+		{MaxTime, false},
+		{time.Unix(1300816219, 0), false},
+		{MinTime.Add(-time.Second), true},
+		{MaxTime.Add(time.Second), true},
+		{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), true},
+	} {
+		id, err := NewWithTimeChecked(tt.t)
+		if tt.wantErr {
+			var rerr *TimeRangeError
+			if !errors.As(err, &rerr) || rerr.Time != tt.t {
+				t.Errorf("NewWithTimeChecked(%v) err=%v, want *TimeRangeError", tt.t, err)
+			}
+			if !id.IsNil() {
+				t.Errorf("NewWithTimeChecked(%v) = %v, want nil ID", tt.t, id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewWithTimeChecked(%v) err=%v", tt.t, err)
+		}
+		if got, want := id.Time(), tt.t; !got.Equal(want) {
+			t.Errorf("Time() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNewWithTimeTruncates(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+		MinTime.Add(-time.Second),
+		MaxTime.Add(time.Second),
+	} {
+		id := NewWithTime(tm)
+		if got, want := id.Time(), time.Unix(int64(uint32(tm.Unix())), 0); !got.Equal(want) {
+			t.Errorf("NewWithTime(%v).Time() = %v, want %v", tm, got, want)
+		}
+	}
+	g := NewGenerator(WithExhaustionPolicy(ExhaustionError))
+	if got, want := g.NewWithTime(MinTime.Add(-time.Second)).Time(), MaxTime; !got.Equal(want) {
+		t.Errorf("NewWithTime().Time() = %v, want %v", got, want)
+	}
+}
+
 func TestIDString(t *testing.T) {
 	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
 	if got, want := id.String(), "9m4e2mr0ui3e8a215n4g"; got != want {
@@ -133,6 +184,73 @@ func TestIDEncode(t *testing.T) {
 	}
 }
 
+func TestIDAppendText(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	got, err := id.AppendText([]byte("id="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id=9m4e2mr0ui3e8a215n4g"; string(got) != want {
+		t.Errorf("AppendText() = %s, want %v", got, want)
+	}
+}
+
+func TestIDAppendJSON(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, tt := range []struct {
+		id   ID
+		want string
+	}{
+		{id, `{"id":"9m4e2mr0ui3e8a215n4g"`},
+		{nilID, `{"id":null`},
+	} {
+		got, err := tt.id.AppendJSON([]byte(`{"id":`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("AppendJSON() = %s, want %v", got, tt.want)
+		}
+		marshaled, _ := tt.id.MarshalJSON()
+		if want := `{"id":` + string(marshaled); string(got) != want {
+			t.Errorf("AppendJSON() = %s, want %v", got, want)
+		}
+	}
+}
+
+func TestIDAppendNoAlloc(t *testing.T) {
+	id := New()
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = id.AppendText(buf[:0])
+		buf, _ = id.AppendJSON(buf[:0])
+		m := map[Text]int{}
+		m[id.Text()]++
+	})
+	if allocs != 0 {
+		t.Errorf("AppendText/AppendJSON/Text allocated %v times, want 0", allocs)
+	}
+}
+
+func TestText(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	text := id.Text()
+	if got, want := text.String(), "9m4e2mr0ui3e8a215n4g"; got != want {
+		t.Errorf("Text().String() = %v, want %v", got, want)
+	}
+	got, err := text.ID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("Text().ID() = %v, want %v", got, id)
+	}
+	var invalid Text
+	if _, err := invalid.ID(); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Text{}.ID() err=%v, want %v", err, ErrInvalidID)
+	}
+}
+
 func TestFromString(t *testing.T) {
 	got, err := FromString("9m4e2mr0ui3e8a215n4g")
 	if err != nil {
@@ -146,7 +264,7 @@ func TestFromString(t *testing.T) {
 
 func TestFromStringInvalid(t *testing.T) {
 	_, err := FromString("invalid")
-	if err != ErrInvalidID {
+	if !errors.Is(err, ErrInvalidID) {
 		t.Errorf("FromString(invalid) err=%v, want %v", err, ErrInvalidID)
 	}
 	id, err := FromString("c6e52g2mrqcjl44hf179")
@@ -155,6 +273,63 @@ func TestFromStringInvalid(t *testing.T) {
 	}
 }
 
+func TestParseError(t *testing.T) {
+	for _, tt := range []struct {
+		parse  func(s string) error
+		input  string
+		offset int
+		reason ParseErrorReason
+	}{
+		{fromString, "invalid", 7, ReasonLength},
+		{fromString, "9m4e2mr0ui3e8a215n4g0", 20, ReasonLength},
+		{fromString, "9m4e2mr0ui3e8a215n4w", 19, ReasonChar},
+		{fromString, "9M4E2MR0UI3E8A215N4G", 1, ReasonChar},
+		{fromString, "c6e52g2mrqcjl44hf179", 19, ReasonNonCanonical},
+		{unmarshalJSON, `"9m4e2mr0ui3e8a215n4w"`, 20, ReasonChar},
+		{unmarshalJSON, `"9m4e2mr0ui3e8a215n4"`, 20, ReasonLength},
+		{unmarshalJSON, `1`, 1, ReasonLength},
+		{unmarshalJSON, `12345678901234567890123`, 0, ReasonChar},
+		{unmarshalJSON, `"9m4e2mr0ui3e8a215n4g`, 20, ReasonChar},
+		{fromBytes, "short", 5, ReasonLength},
+		{fromBytes, "thirteen byte", 12, ReasonLength},
+	} {
+		err := tt.parse(tt.input)
+		if !errors.Is(err, ErrInvalidID) {
+			t.Errorf("parse(%q) err=%v, want %v", tt.input, err, ErrInvalidID)
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("parse(%q) err=%v, want *ParseError", tt.input, err)
+			continue
+		}
+		if got, want := *perr, (ParseError{Input: tt.input, Offset: tt.offset, Reason: tt.reason}); got != want {
+			t.Errorf("parse(%q) err=%+v, want %+v", tt.input, got, want)
+		}
+	}
+}
+
+func fromString(s string) error {
+	_, err := FromString(s)
+	return err
+}
+
+func unmarshalJSON(s string) error {
+	var id ID
+	return id.UnmarshalJSON([]byte(s))
+}
+
+func fromBytes(s string) error {
+	_, err := FromBytes([]byte(s))
+	return err
+}
+
+func TestParseErrorString(t *testing.T) {
+	err := &ParseError{Input: "9m4e2mr0ui3e8a215n4w", Offset: 19, Reason: ReasonChar}
+	if got, want := err.Error(), `xid: invalid ID: invalid char at offset 19 in "9m4e2mr0ui3e8a215n4w"`; got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+}
+
 type jsonType struct {
 	ID  *ID
 	Str string
@@ -189,23 +364,100 @@ func TestIDJSONUnmarshaling(t *testing.T) {
 func TestIDJSONUnmarshalingError(t *testing.T) {
 	v := jsonType{}
 	err := json.Unmarshal([]byte(`{"ID":"9M4E2MR0UI3E8A215N4G"}`), &v)
-	if err != ErrInvalidID {
+	if !errors.Is(err, ErrInvalidID) {
 		t.Errorf("json.Unmarshal() err=%v, want %v", err, ErrInvalidID)
 	}
 	err = json.Unmarshal([]byte(`{"ID":"TYjhW2D0huQoQS"}`), &v)
-	if err != ErrInvalidID {
+	if !errors.Is(err, ErrInvalidID) {
 		t.Errorf("json.Unmarshal() err=%v, want %v", err, ErrInvalidID)
 	}
 	err = json.Unmarshal([]byte(`{"ID":"TYjhW2D0huQoQS3kdk"}`), &v)
-	if err != ErrInvalidID {
+	if !errors.Is(err, ErrInvalidID) {
 		t.Errorf("json.Unmarshal() err=%v, want %v", err, ErrInvalidID)
 	}
 	err = json.Unmarshal([]byte(`{"ID":1}`), &v)
-	if err != ErrInvalidID {
+	if !errors.Is(err, ErrInvalidID) {
 		t.Errorf("json.Unmarshal() err=%v, want %v", err, ErrInvalidID)
 	}
 }
 
+func TestIDBinaryMarshaling(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	b, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, id[:]) {
+		t.Errorf("MarshalBinary() = %v, want %v", b, id[:])
+	}
+	b, err = id.AppendBinary([]byte{0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte{0x01}, id[:]...); !bytes.Equal(b, want) {
+		t.Errorf("AppendBinary() = %v, want %v", b, want)
+	}
+	var got ID
+	if err := got.UnmarshalBinary(id[:]); err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("UnmarshalBinary() = %v, want %v", got, id)
+	}
+	if err := got.UnmarshalBinary(id[:11]); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("UnmarshalBinary() err=%v, want %v", err, ErrInvalidID)
+	}
+}
+
+type gobType struct {
+	ID   ID
+	Ptr  *ID
+	List []ID
+	Str  string
+}
+
+func TestIDGob(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	want := gobType{ID: id, Ptr: &id, List: []ID{id, nilID, New()}, Str: "test"}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(want); err != nil {
+		t.Fatal(err)
+	}
+	var got gobType
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gob round trip = %+v, want %+v", got, want)
+	}
+}
+
+// textID encodes as ID did before it implemented encoding.BinaryMarshaler.
+type textID ID
+
+func (id textID) MarshalText() ([]byte, error) {
+	return ID(id).MarshalText()
+}
+
+func TestIDGobWireFormat(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(struct{ ID ID }{id}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), id[:]) || bytes.Contains(buf.Bytes(), []byte(id.String())) {
+		t.Errorf("gob data %x, want the raw bytes of the id", buf.Bytes())
+	}
+	buf.Reset()
+	if err := gob.NewEncoder(&buf).Encode(struct{ ID textID }{textID(id)}); err != nil {
+		t.Fatal(err)
+	}
+	var got struct{ ID ID }
+	if err := gob.NewDecoder(&buf).Decode(&got); err == nil || !strings.Contains(err.Error(), "wrong type") {
+		t.Errorf("decoding text encoded id: err=%v, want a wrong type error", err)
+	}
+}
+
 func TestIDDriverValue(t *testing.T) {
 	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
 	got, err := id.Value()
@@ -234,7 +486,7 @@ func TestIDDriverScanError(t *testing.T) {
 	if got, want := id.Scan(0), errors.New("xid: scanning unsupported type: int"); !reflect.DeepEqual(got, want) {
 		t.Errorf("Scan() err=%v, want %v", got, want)
 	}
-	if got, want := id.Scan("0"), ErrInvalidID; got != want {
+	if got, want := id.Scan("0"), ErrInvalidID; !errors.Is(got, want) {
 		t.Errorf("Scan() err=%v, want %v", got, want)
 	}
 }
@@ -268,6 +520,17 @@ func BenchmarkNewString(b *testing.B) {
 	})
 }
 
+func BenchmarkAppendText(b *testing.B) {
+	id := New()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		buf := make([]byte, 0, encodedLen)
+		for pb.Next() {
+			buf, _ = id.AppendText(buf[:0])
+		}
+	})
+}
+
 func BenchmarkFromString(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -476,9 +739,9 @@ func TestID_Compare(t *testing.T) {
 		right    ID
 		expected int
 	}{
-		{IDs[1].id, IDs[0].id, -1},
-		{ID{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, IDs[2].id, -1},
-		{IDs[0].id, IDs[0].id, 0},
+		{testIDs[1].id, testIDs[0].id, -1},
+		{ID{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, testIDs[2].id, -1},
+		{testIDs[0].id, testIDs[0].id, 0},
 	}
 	for _, p := range pairs {
 		if p.expected != p.left.Compare(p.right) {
@@ -490,7 +753,7 @@ func TestID_Compare(t *testing.T) {
 	}
 }
 
-var IDList = []ID{IDs[0].id, IDs[1].id, IDs[2].id}
+var IDList = []ID{testIDs[0].id, testIDs[1].id, testIDs[2].id}
 
 func TestSorter_Len(t *testing.T) {
 	if got, want := sorter([]ID{}).Len(), 0; got != want {
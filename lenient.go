@@ -0,0 +1,47 @@
+package xid
+
+import "strings"
+
+// LenientOption tunes the behavior of ParseLenient.
+type LenientOption int
+
+const (
+	// FixConfusables makes ParseLenient read an o as 0 in the last char of an
+	// id, which can only be 0 or g. Elsewhere, o is a valid xid char.
+	FixConfusables LenientOption = 1 << iota
+)
+
+// lenientCutset contains the chars trimmed around the input of ParseLenient,
+// including the non-breaking space and typographic quotes added by text
+// editors.
+const lenientCutset = " \t\r\n\u00a0\"'`\u2018\u2019\u201c\u201d"
+
+// ParseLenient reads an ID from its base32 representation as typed or pasted
+// by a human. Unlike FromString, it ignores surrounding whitespace and quotes
+// and accepts uppercase chars. Errors are reported on s.
+func ParseLenient(s string, opts ...LenientOption) (ID, error) {
+	var flags LenientOption
+	for _, opt := range opts {
+		flags |= opt
+	}
+	trimmed := strings.TrimLeft(s, lenientCutset)
+	lead := len(s) - len(trimmed)
+	// Only lower ASCII chars, so that offsets in text are offsets in s.
+	text := []byte(strings.TrimRight(trimmed, lenientCutset))
+	for i, c := range text {
+		if 'A' <= c && c <= 'Z' {
+			text[i] = c + 'a' - 'A'
+		}
+	}
+	if flags&FixConfusables != 0 && len(text) == encodedLen && text[encodedLen-1] == 'o' {
+		text[encodedLen-1] = '0'
+	}
+	id, err := FromString(string(text))
+	if err != nil {
+		// Report the error on the original input
+		perr := err.(*ParseError)
+		perr.Input = s
+		perr.Offset += lead
+	}
+	return id, err
+}
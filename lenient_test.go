@@ -0,0 +1,77 @@
+package xid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLenient(t *testing.T) {
+	want := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, s := range []string{
+		"9m4e2mr0ui3e8a215n4g",
+		"9M4E2MR0UI3E8A215N4G",
+		"  9m4e2mr0ui3e8a215n4g\n",
+		`"9m4e2mr0ui3e8a215n4g"`,
+		` '9M4E2MR0UI3E8A215N4G' `,
+		"`9m4e2mr0ui3e8a215n4g`",
+	} {
+		got, err := ParseLenient(s)
+		if err != nil {
+			t.Errorf("ParseLenient(%q) err=%v", s, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseLenient(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestParseLenientConfusables(t *testing.T) {
+	// c6e52g2mrqcjl44hf170 with the last 0 typed as O
+	const s = "C6E52G2MRQCJL44HF17O"
+	if _, err := ParseLenient(s); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("ParseLenient(%q) err=%v, want %v", s, err, ErrInvalidID)
+	}
+	got, err := ParseLenient(s, FixConfusables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "c6e52g2mrqcjl44hf170"; got.String() != want {
+		t.Errorf("ParseLenient(%q) = %v, want %v", s, got, want)
+	}
+}
+
+func TestParseLenientTypographicQuotes(t *testing.T) {
+	const s = "“9m4e2mr0ui3e8a215n4g” "
+	if got, err := ParseLenient(s); err != nil || got.String() != "9m4e2mr0ui3e8a215n4g" {
+		t.Errorf("ParseLenient(%q) = %v, %v", s, got, err)
+	}
+}
+
+func TestParseLenientInvalid(t *testing.T) {
+	for _, s := range []string{"", `""`, "9m4e2mr0ui3e8a215n4", "9m4e 2mr0ui3e8a215n4g", "9m4e2mr0ui3e8a215n4w", "9m4e2mr0ui3e8a215n4l"} {
+		if _, err := ParseLenient(s, FixConfusables); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("ParseLenient(%q) err=%v, want %v", s, err, ErrInvalidID)
+		}
+	}
+}
+
+func TestParseLenientError(t *testing.T) {
+	const s = ` "9M4E2MR0UI3E8A215N4H" `
+	_, err := ParseLenient(s, FixConfusables)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("ParseLenient(%q) err=%v, want a *ParseError", s, err)
+	}
+	if perr.Input != s || perr.Offset != 21 || perr.Reason != ReasonNonCanonical {
+		t.Errorf("ParseLenient(%q) err=%+v, want a non canonical last char at offset 21 of the input", s, perr)
+	}
+}
+
+func TestFromStringStrict(t *testing.T) {
+	for _, s := range []string{"9M4E2MR0UI3E8A215N4G", " 9m4e2mr0ui3e8a215n4g", `"9m4e2mr0ui3e8a215n4g"`} {
+		if _, err := FromString(s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("FromString(%q) err=%v, want %v", s, err, ErrInvalidID)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package xid
+
+import "os"
+
+// lockFile is a no-op on platforms without file locking support.
+func lockFile(f *os.File) error {
+	return nil
+}
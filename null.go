@@ -0,0 +1,56 @@
+package xid
+
+import (
+	"database/sql/driver"
+)
+
+// NullID represents an ID that may be null. Unlike ID, which maps the nil ID
+// to SQL NULL, it distinguishes a NULL value from a stored nil ID, like
+// sql.NullString does for strings.
+type NullID struct {
+	ID    ID
+	Valid bool // Valid is true if ID is not NULL
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullID) Scan(value interface{}) error {
+	if value == nil {
+		n.ID, n.Valid = nilID, false
+		return nil
+	}
+	err := n.ID.Scan(value)
+	n.Valid = err == nil
+	return err
+}
+
+// Value implements the driver.Valuer interface. A valid nil ID is stored as
+// its string representation, not as NULL.
+func (n NullID) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.ID.String(), nil
+}
+
+// MarshalJSON implements encoding/json Marshaler interface. A valid nil ID is
+// marshaled as its string representation, not as null.
+func (n NullID) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	text := make([]byte, 0, encodedLen+2)
+	text = append(text, '"')
+	text, _ = n.ID.AppendText(text)
+	return append(text, '"'), nil
+}
+
+// UnmarshalJSON implements encoding/json Unmarshaler interface
+func (n *NullID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		n.ID, n.Valid = nilID, false
+		return nil
+	}
+	err := n.ID.UnmarshalJSON(b)
+	n.Valid = err == nil
+	return err
+}
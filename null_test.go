@@ -0,0 +1,87 @@
+package xid
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNullIDScan(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, tt := range []struct {
+		name    string
+		value   interface{}
+		want    NullID
+		wantErr bool
+	}{
+		{"null", nil, NullID{}, false},
+		{"string", "9m4e2mr0ui3e8a215n4g", NullID{ID: id, Valid: true}, false},
+		{"bytes", []byte("9m4e2mr0ui3e8a215n4g"), NullID{ID: id, Valid: true}, false},
+		{"nil id", "00000000000000000000", NullID{Valid: true}, false},
+		{"invalid", "invalid", NullID{}, true},
+		{"unsupported", 1, NullID{}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NullID{ID: New(), Valid: true}
+			err := got.Scan(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Scan() err=%v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Valid != tt.want.Valid || (!tt.wantErr && got.ID != tt.want.ID) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNullIDValue(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, tt := range []struct {
+		n    NullID
+		want interface{}
+	}{
+		{NullID{}, nil},
+		{NullID{ID: id}, nil},
+		{NullID{ID: id, Valid: true}, "9m4e2mr0ui3e8a215n4g"},
+		{NullID{Valid: true}, "00000000000000000000"},
+	} {
+		got, err := tt.n.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%+v.Value() = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNullIDJSON(t *testing.T) {
+	id := ID{0x4d, 0x88, 0xe1, 0x5b, 0x60, 0xf4, 0x86, 0xe4, 0x28, 0x41, 0x2d, 0xc9}
+	for _, tt := range []struct {
+		n    NullID
+		json string
+	}{
+		{NullID{}, `null`},
+		{NullID{ID: id, Valid: true}, `"9m4e2mr0ui3e8a215n4g"`},
+		{NullID{Valid: true}, `"00000000000000000000"`},
+	} {
+		b, err := json.Marshal(tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.json {
+			t.Errorf("json.Marshal(%+v) = %s, want %s", tt.n, b, tt.json)
+		}
+		got := NullID{ID: New(), Valid: true}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.n {
+			t.Errorf("json.Unmarshal(%s) = %+v, want %+v", b, got, tt.n)
+		}
+	}
+	var n NullID
+	if err := json.Unmarshal([]byte(`"invalid"`), &n); !errors.Is(err, ErrInvalidID) || n.Valid {
+		t.Errorf("json.Unmarshal() = %+v, %v, want %v", n, err, ErrInvalidID)
+	}
+}
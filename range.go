@@ -0,0 +1,100 @@
+package xid
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// MinID returns the smallest possible ID generated during the second of t,
+// i.e. with its machine id, pid and counter bytes set to zero. Times outside
+// of the [MinTime, MaxTime] range are clamped to it.
+func MinID(t time.Time) ID {
+	var id ID
+	binary.BigEndian.PutUint32(id[:], clampSecs(t.Unix()))
+	return id
+}
+
+// MaxID returns the largest possible ID generated during the second of t,
+// i.e. with its machine id, pid and counter bytes set to 0xff. Times outside
+// of the [MinTime, MaxTime] range are clamped to it.
+func MaxID(t time.Time) ID {
+	id := ID{4: 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	binary.BigEndian.PutUint32(id[:], clampSecs(t.Unix()))
+	return id
+}
+
+// clampSecs returns secs clamped to the range of the 4-byte timestamp.
+func clampSecs(secs int64) uint32 {
+	switch {
+	case secs < 0:
+		return 0
+	case secs > math.MaxUint32:
+		return math.MaxUint32
+	}
+	return uint32(secs)
+}
+
+// IDRange is an inclusive range of IDs, from Min to Max. It's empty when Min
+// is greater than Max.
+type IDRange struct {
+	Min ID
+	Max ID
+}
+
+// NewIDRange returns the range of all the IDs generated from the second of
+// start to the second of end included. For instance, the rows created between
+// T1 and T2 are selected with:
+//
+//	r := xid.NewIDRange(t1, t2)
+//	db.Query("SELECT * FROM t WHERE id BETWEEN $1 AND $2", r.Min, r.Max)
+func NewIDRange(start, end time.Time) IDRange {
+	return IDRange{Min: MinID(start), Max: MaxID(end)}
+}
+
+// IsEmpty returns true if the range doesn't contain any ID.
+func (r IDRange) IsEmpty() bool {
+	return r.Min.Compare(r.Max) > 0
+}
+
+// Contains returns true if id is within the range.
+func (r IDRange) Contains(id ID) bool {
+	return r.Min.Compare(id) <= 0 && id.Compare(r.Max) <= 0
+}
+
+// Overlaps returns true if the range and other have at least one ID in
+// common.
+func (r IDRange) Overlaps(other IDRange) bool {
+	return !r.IsEmpty() && !other.IsEmpty() &&
+		r.Min.Compare(other.Max) <= 0 && other.Min.Compare(r.Max) <= 0
+}
+
+// Split splits the range into consecutive ranges covering by of time each,
+// the last one being possibly shorter. The duration is truncated to the
+// second, with a minimum of one second. The ranges don't overlap and together
+// contain the same IDs as r. Split returns nil if r is empty.
+func (r IDRange) Split(by time.Duration) []IDRange {
+	if r.IsEmpty() {
+		return nil
+	}
+	step := int64(by / time.Second)
+	if step < 1 {
+		step = 1
+	}
+	start, end := r.Min.Time().Unix(), r.Max.Time().Unix()
+	ranges := make([]IDRange, 0, (end-start)/step+1)
+	for secs := start; secs <= end; secs += step {
+		sub := IDRange{
+			Min: MinID(time.Unix(secs, 0)),
+			Max: MaxID(time.Unix(secs+step-1, 0)),
+		}
+		if secs == start {
+			sub.Min = r.Min
+		}
+		if secs+step > end {
+			sub.Max = r.Max
+		}
+		ranges = append(ranges, sub)
+	}
+	return ranges
+}
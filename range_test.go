@@ -0,0 +1,82 @@
+package xid
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMinMaxID(t *testing.T) {
+	now := time.Unix(1300816219, 500)
+	lo, hi := MinID(now), MaxID(now)
+	if got, want := lo.String(), "9m4e2mo0000000000000"; got != want {
+		t.Errorf("MinID() = %v, want %v", got, want)
+	}
+	if got, want := hi.String(), "9m4e2mvvvvvvvvvvvvvg"; got != want {
+		t.Errorf("MaxID() = %v, want %v", got, want)
+	}
+	id := NewGenerator().NewWithTime(now)
+	if lo.Compare(id) >= 0 || id.Compare(hi) >= 0 {
+		t.Errorf("%v is not between %v and %v", id, lo, hi)
+	}
+	if got, want := MinID(time.Unix(-1, 0)).Time(), MinTime; !got.Equal(want) {
+		t.Errorf("MinID().Time() = %v, want %v", got, want)
+	}
+	if got, want := MaxID(MaxTime.Add(time.Hour)).Time(), MaxTime; !got.Equal(want) {
+		t.Errorf("MaxID().Time() = %v, want %v", got, want)
+	}
+}
+
+func TestIDRange(t *testing.T) {
+	now := time.Unix(1300816219, 0)
+	g := NewGenerator()
+	r := NewIDRange(now, now.Add(time.Minute))
+	for _, tt := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{now.Add(-time.Second), false},
+		{now, true},
+		{now.Add(time.Minute), true},
+		{now.Add(time.Minute + time.Second), false},
+	} {
+		if got := r.Contains(g.NewWithTime(tt.t)); got != tt.want {
+			t.Errorf("Contains(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+	tests := []struct {
+		other IDRange
+		want  bool
+	}{
+		{NewIDRange(now.Add(-time.Hour), now.Add(-time.Second)), false},
+		{NewIDRange(now.Add(-time.Hour), now), true},
+		{NewIDRange(now.Add(time.Second), now.Add(time.Second)), true},
+		{NewIDRange(now.Add(time.Minute), now.Add(time.Hour)), true},
+		{NewIDRange(now.Add(time.Minute+time.Second), now.Add(time.Hour)), false},
+		{NewIDRange(now.Add(time.Second), now), false},
+	}
+	for _, tt := range tests {
+		if got := r.Overlaps(tt.other); got != tt.want {
+			t.Errorf("Overlaps(%v) = %v, want %v", tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestIDRangeSplit(t *testing.T) {
+	now := time.Unix(1300816219, 0)
+	r := IDRange{Min: NewGenerator().NewWithTime(now), Max: MaxID(now.Add(150 * time.Second))}
+	want := []IDRange{
+		{r.Min, MaxID(now.Add(59 * time.Second))},
+		{MinID(now.Add(60 * time.Second)), MaxID(now.Add(119 * time.Second))},
+		{MinID(now.Add(120 * time.Second)), r.Max},
+	}
+	if got := r.Split(time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("Split() = %v, want %v", got, want)
+	}
+	if got := r.Split(0); len(got) != 151 {
+		t.Errorf("len(Split(0)) = %v, want 151", len(got))
+	}
+	if got := NewIDRange(now, now.Add(-time.Second)).Split(time.Minute); got != nil {
+		t.Errorf("Split() = %v, want nil", got)
+	}
+}
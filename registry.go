@@ -0,0 +1,87 @@
+package xid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Registry maps machine ids back to the labels of the hosts they may have
+// been derived from. As machine ids are only 3 bytes long, several hosts of a
+// large fleet may share the same machine id, hence the candidate labels.
+type Registry struct {
+	hosts map[[3]byte][]string
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{hosts: map[[3]byte][]string{}}
+}
+
+// LoadRegistry reads a registry from the file at path (see ReadRegistry).
+func LoadRegistry(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRegistry(f)
+}
+
+// ReadRegistry reads a registry with one host per line: a label followed by
+// the platform machine id or hostname of the host, separated by whitespace.
+// A line with a single field uses it as both the label and the source, so a
+// list of hostnames is a valid registry. Empty lines and lines starting with #
+// are ignored.
+func ReadRegistry(r io.Reader) (*Registry, error) {
+	reg := NewRegistry()
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0 || strings.HasPrefix(fields[0], "#"):
+		case len(fields) == 1:
+			reg.Add(fields[0], fields[0])
+		case len(fields) == 2:
+			reg.Add(fields[0], fields[1])
+		default:
+			return nil, fmt.Errorf("xid: registry line %d: expected a label and a source, got %d fields", n, len(fields))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// Add registers the host labeled label, whose machine id is derived from
+// source, a platform machine id or hostname (see MachineIDFor). As the Linux
+// machine id is hashed with the trailing newline of /etc/machine-id, source is
+// registered both with and without a trailing newline.
+func (r *Registry) Add(label, source string) {
+	source = strings.TrimRight(source, "\n")
+	r.add(MachineIDFor(source), label)
+	r.add(MachineIDFor(source+"\n"), label)
+}
+
+func (r *Registry) add(machine [3]byte, label string) {
+	for _, l := range r.hosts[machine] {
+		if l == label {
+			return
+		}
+	}
+	r.hosts[machine] = append(r.hosts[machine], label)
+}
+
+// Lookup returns the labels of the hosts whose machine id is machine, as
+// returned by ID.Machine, in the order they were added.
+func (r *Registry) Lookup(machine []byte) []string {
+	var key [3]byte
+	if len(machine) != len(key) {
+		return nil
+	}
+	copy(key[:], machine)
+	return r.hosts[key]
+}
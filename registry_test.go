@@ -0,0 +1,67 @@
+package xid
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMachineIDFor(t *testing.T) {
+	hid, err := readPlatformMachineID()
+	if err != nil || hid == "" {
+		t.Skip("no platform machine id")
+	}
+	if got := MachineIDFor(hid); !bytes.Equal(got[:], machineID) && readMachineIDFromEnv() == nil {
+		t.Errorf("MachineIDFor(%q) = %x, want %x", hid, got, machineID)
+	}
+	if got, want := MachineIDFor("host1"), [3]byte{0xc0, 0x36, 0x5b}; got != want {
+		t.Errorf("MachineIDFor(\"host1\") = %x, want %x", got, want)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r, err := ReadRegistry(strings.NewReader(`
+# fleet
+web1
+db1 2f5b0b8e1c1f4a8c9a0e6c3b2d1e0f9a
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	web1 := MachineIDFor("web1")
+	if got, want := r.Lookup(web1[:]), []string{"web1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup(web1) = %v, want %v", got, want)
+	}
+	db1 := MachineIDFor("2f5b0b8e1c1f4a8c9a0e6c3b2d1e0f9a\n")
+	if got, want := r.Lookup(db1[:]), []string{"db1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup(db1) = %v, want %v", got, want)
+	}
+	id := NewGenerator(WithMachineID(db1)).New()
+	if got, want := r.Lookup(id.Machine()), []string{"db1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup(id.Machine()) = %v, want %v", got, want)
+	}
+	if got := r.Lookup([]byte{1, 2, 3}); got != nil {
+		t.Errorf("Lookup(unknown) = %v, want nil", got)
+	}
+	if got := r.Lookup(nil); got != nil {
+		t.Errorf("Lookup(nil) = %v, want nil", got)
+	}
+}
+
+func TestRegistryCollision(t *testing.T) {
+	r := NewRegistry()
+	r.Add("a", "host")
+	r.Add("b", "host")
+	r.Add("a", "host")
+	h := MachineIDFor("host")
+	if got, want := r.Lookup(h[:]), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup() = %v, want %v", got, want)
+	}
+}
+
+func TestReadRegistryInvalid(t *testing.T) {
+	if _, err := ReadRegistry(strings.NewReader("web1 host1 extra\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("ReadRegistry() err=%v, want an error on line 1", err)
+	}
+}
@@ -0,0 +1,72 @@
+package xid
+
+import (
+	"sync"
+)
+
+// defaultBlockSize is the number of counter values reserved at once by the
+// shards of a sharded generator when no block size is given.
+const defaultBlockSize = 1024
+
+// counterBlock is a range of counter values reserved by a shard of a sharded
+// generator for a given timestamp.
+type counterBlock struct {
+	secs uint32
+	next uint32
+	left uint32
+}
+
+// WithShardedCounter makes the generator shard its counter to remove the
+// contention on a single atomic counter when generating ids from many cores.
+// Each shard reserves blocks of blockSize counter values from the shared
+// counter and hands them out without synchronization. Shards are cached per
+// P (see sync.Pool), so throughput scales with GOMAXPROCS. A blockSize <= 0
+// uses a default of 1024.
+//
+// Ids remain unique, but are no longer ordered by generation time within a
+// second: two ids generated in sequence, even by the same goroutine, may come
+// from different blocks and have decreasing counters. Unused counter values
+// of a block are lost when the second changes or when the block is dropped by
+// the garbage collector, which reduces the number of ids that can be generated
+// within a second by up to blockSize per shard.
+//
+// The sharded counter is only used by generators that don't track the ids
+// they generate: it has no effect on monotonic generators or when an
+// exhaustion policy is set. Batches generated with Fill or NewBatch are
+// reserved from the shared counter.
+func WithShardedCounter(blockSize int) Option {
+	return func(g *Generator) {
+		if blockSize <= 0 {
+			blockSize = defaultBlockSize
+		}
+		if blockSize > counterSpace {
+			blockSize = counterSpace
+		}
+		g.blockSize = uint32(blockSize)
+		g.shards = &sync.Pool{}
+	}
+}
+
+// reserveSharded returns a counter value for the timestamp secs from the
+// block of the current shard, reserving a new block if needed.
+func (g *Generator) reserveSharded(secs uint32) (uint32, error) {
+	b, _ := g.shards.Get().(*counterBlock)
+	if b == nil {
+		b = &counterBlock{}
+	}
+	if b.left == 0 || b.secs != secs {
+		end, err := g.advance(g.blockSize)
+		if err != nil {
+			g.shards.Put(b)
+			return 0, err
+		}
+		b.secs = secs
+		b.next = end - (g.blockSize - 1)
+		b.left = g.blockSize
+	}
+	i := b.next
+	b.next++
+	b.left--
+	g.shards.Put(b)
+	return i, nil
+}
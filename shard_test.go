@@ -0,0 +1,46 @@
+package xid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShardedCounterUnique(t *testing.T) {
+	assertConcurrentUnique(t, NewGenerator(WithShardedCounter(16)))
+}
+
+func TestShardedCounterSecondChange(t *testing.T) {
+	c := NewManualClock(time.Unix(1300816219, 0))
+	g := NewGenerator(WithClock(c), WithShardedCounter(16), WithCounter(0))
+	first := g.New()
+	c.Advance(time.Second)
+	// The block reserved for the previous second is dropped.
+	if got, want := g.New().Counter(), first.Counter()+16; got != want {
+		t.Errorf("Counter() = %v, want %v", got, want)
+	}
+}
+
+func TestShardedCounterBlockSize(t *testing.T) {
+	for _, tt := range []struct {
+		size int
+		want uint32
+	}{
+		{0, defaultBlockSize},
+		{-1, defaultBlockSize},
+		{64, 64},
+		{counterSpace + 1, counterSpace},
+	} {
+		if got := NewGenerator(WithShardedCounter(tt.size)).blockSize; got != tt.want {
+			t.Errorf("WithShardedCounter(%d) block size = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkGeneratorNewSharded(b *testing.B) {
+	g := NewGenerator(WithShardedCounter(0))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = g.New()
+		}
+	})
+}
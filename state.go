@@ -0,0 +1,140 @@
+package xid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// stateMagic prefixes the content of state files.
+	stateMagic = "xid1"
+
+	// stateLen is the size of the content of state files: the magic, the
+	// timestamp and the counter.
+	stateLen = 12
+
+	// stateWindow is the number of counter values covered by each checkpoint
+	// of a state file.
+	stateWindow = 1 << 16
+)
+
+// StateFile persists the counter of a generator so a restarted process resumes
+// from a known-safe position instead of a random one. This prevents a process
+// restarting within the same second with a recycled pid from reusing counter
+// values.
+//
+// The file is locked while open so two processes can't share it, except on
+// the platforms without file locking support (other than the BSDs, Linux,
+// macOS and Windows), where it's up to the caller to ensure it. It must be
+// used by a single generator.
+type StateFile struct {
+	f *os.File
+
+	// loaded reports whether secs and counter were read from the file.
+	loaded  bool
+	secs    uint32
+	counter uint32
+
+	// mu serializes checkpoints.
+	mu sync.Mutex
+
+	// limit is the first counter value not covered by the last checkpoint. It
+	// is accessed atomically.
+	limit uint32
+}
+
+// OpenStateFile opens the state file at path, creating it if needed, locks it
+// and reads the state it contains. It returns ErrStateLocked if the file is
+// locked by another process.
+func OpenStateFile(path string) (*StateFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := &StateFile{f: f}
+	var b [stateLen]byte
+	switch _, err := io.ReadFull(f, b[:]); {
+	case err == io.EOF:
+		// New file
+	case err != nil:
+		f.Close()
+		return nil, fmt.Errorf("xid: cannot read state file %s: %v", path, err)
+	case string(b[:4]) != stateMagic:
+		f.Close()
+		return nil, fmt.Errorf("xid: invalid state file %s", path)
+	default:
+		s.loaded = true
+		s.secs = binary.BigEndian.Uint32(b[4:8])
+		s.counter = binary.BigEndian.Uint32(b[8:12])
+	}
+	return s, nil
+}
+
+// Close releases the lock and closes the state file.
+func (s *StateFile) Close() error {
+	return s.f.Close()
+}
+
+// write writes the timestamp secs and the counter to the file and syncs it.
+func (s *StateFile) write(secs, counter uint32) error {
+	var b [stateLen]byte
+	copy(b[:], stateMagic)
+	binary.BigEndian.PutUint32(b[4:8], secs)
+	binary.BigEndian.PutUint32(b[8:12], counter)
+	if _, err := s.f.WriteAt(b[:], 0); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+// WithStateFile makes the generator persist its counter in s. When s contains
+// a state, the counter resumes from it and the time of the last checkpoint is
+// used as the latest time seen by the rollback policy.
+//
+// The generator checkpoints the file each time it consumes a window of 65536
+// counter values, before handing out any value of the window. Generation
+// fails if a checkpoint can't be written.
+func WithStateFile(s *StateFile) Option {
+	return func(g *Generator) {
+		g.state = s
+	}
+}
+
+// restoreState initializes the generator from its state file.
+func (g *Generator) restoreState() {
+	s := g.state
+	if s.loaded {
+		g.counter = s.counter - 1
+		if int64(s.secs) > g.lastClock {
+			g.lastClock = int64(s.secs)
+		}
+	}
+	// Make the next generation checkpoint.
+	atomic.StoreUint32(&s.limit, g.counter+1)
+}
+
+// checkpoint makes sure the counter value i is covered by a checkpoint of the
+// state file.
+func (g *Generator) checkpoint(i uint32) error {
+	s := g.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if int32(i-atomic.LoadUint32(&s.limit)) < 0 {
+		// Covered by a concurrent checkpoint
+		return nil
+	}
+	limit := i + stateWindow
+	if err := s.write(uint32(g.clock.Now().Unix()), limit); err != nil {
+		return fmt.Errorf("xid: cannot checkpoint state file: %v", err)
+	}
+	atomic.StoreUint32(&s.limit, limit)
+	return nil
+}
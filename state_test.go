@@ -0,0 +1,104 @@
+package xid
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateFileResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xid.state")
+	c := NewManualClock(time.Unix(1300816219, 0))
+
+	s, err := OpenStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(WithClock(c), WithStateFile(s), WithCounter(0x100))
+	var last ID
+	for i := 0; i < 10; i++ {
+		last = g.New()
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = OpenStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if !s.loaded {
+		t.Fatal("state not loaded")
+	}
+	g = NewGenerator(WithClock(c), WithStateFile(s))
+	id := g.New()
+	if got, want := id.Counter(), int32(0x100+stateWindow); got != want {
+		t.Errorf("Counter() = %#x, want %#x", got, want)
+	}
+	if id.Counter() <= last.Counter() {
+		t.Errorf("resumed counter %#x not after %#x", id.Counter(), last.Counter())
+	}
+}
+
+func TestStateFileCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xid.state")
+	s, err := OpenStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	g := NewGenerator(WithStateFile(s), WithCounter(0))
+	if _, err := g.NewBatch(stateWindow + 10); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.limit, uint32(2*stateWindow+9); got != want {
+		t.Errorf("limit = %#x, want %#x", got, want)
+	}
+}
+
+func TestStateFileRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xid.state")
+	start := time.Unix(1300816219, 0)
+	c := NewManualClock(start)
+	s, err := OpenStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewGenerator(WithClock(c), WithStateFile(s)).New()
+	s.Close()
+
+	c.Advance(-time.Minute)
+	s, err = OpenStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	g := NewGenerator(WithClock(c), WithStateFile(s), WithRollbackPolicy(RollbackReuse))
+	if got, want := g.New().Time(), start; got != want {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestStateFileLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xid.state")
+	s, err := OpenStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := OpenStateFile(path); err != ErrStateLocked {
+		t.Errorf("OpenStateFile() err=%v, want %v", err, ErrStateLocked)
+	}
+}
+
+func TestStateFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xid.state")
+	if err := os.WriteFile(path, []byte("not a state file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenStateFile(path); err == nil {
+		t.Error("OpenStateFile() err=nil, want error")
+	}
+}
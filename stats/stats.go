@@ -0,0 +1,245 @@
+// Package stats analyzes a corpus of xids to reconstruct the processes that
+// generated them.
+//
+// The IDs are grouped by machine id and pid. For each group, the analysis
+// reports the time range of the IDs and infers the generation rate of the
+// process from the deltas of the counter, which is incremented for each ID the
+// process generates, whether it's part of the corpus or not. As a process
+// starts its counter at a random value, a jump of the counter that's not
+// plausible for the elapsed time reveals a restart of the process with the
+// same pid, i.e. in containers where processes often have the pid 1.
+//
+// The analysis assumes the IDs were generated by the default generator, or by
+// generators sharing the same counter sequence: the counter of a sharded
+// generator isn't sequential and yields spurious restarts.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// DefaultMaxRate is the default highest plausible generation rate of a
+// process, in ids per second.
+const DefaultMaxRate = 1 << 20
+
+// counterMask masks the 24 bits of the counter.
+const counterMask = 1<<24 - 1
+
+// Analyzer accumulates IDs to analyze. Its zero value is ready to use.
+type Analyzer struct {
+	// MaxRate is the highest plausible generation rate of a process, in ids
+	// per second. A counter jump larger than what this rate allows since the
+	// previous ID of the group is considered as a restart. If zero,
+	// DefaultMaxRate is used.
+	MaxRate float64
+
+	ids []xid.ID
+}
+
+// Add adds ids to the corpus.
+func (a *Analyzer) Add(ids ...xid.ID) {
+	a.ids = append(a.ids, ids...)
+}
+
+// Report is the result of the analysis of a corpus of IDs.
+type Report struct {
+	// Count is the number of IDs analyzed, including the duplicates.
+	Count int
+
+	// Groups are the IDs grouped by machine id and pid, sorted by machine
+	// id and pid.
+	Groups []Group
+
+	// Duplicates are the IDs found more than once in the corpus, sorted.
+	Duplicates []Duplicate
+}
+
+// Group reports on the IDs sharing the same machine id and pid.
+type Group struct {
+	Machine [3]byte
+	Pid     uint16
+
+	// Count is the number of IDs of the group, including the duplicates.
+	Count int
+
+	// First and Last are the timestamps of the first and last IDs.
+	First time.Time
+	Last  time.Time
+
+	// Rate is the average generation rate of the processes of the group
+	// while running, in ids per second.
+	Rate float64
+
+	// Runs are the lifetimes of the processes found in the group, sorted by
+	// time. A group with more than one run reveals restarts of the process.
+	Runs []Run
+
+	// Duplicates is the number of IDs of the group found more than once,
+	// not counting their first occurrence.
+	Duplicates int
+}
+
+// Restarts returns the number of restarts detected in the group.
+func (g Group) Restarts() int {
+	return len(g.Runs) - 1
+}
+
+// Run reports on the IDs generated by a single process.
+type Run struct {
+	// First and Last are the timestamps of the first and last IDs.
+	First time.Time
+	Last  time.Time
+
+	// Count is the number of IDs of the run found in the corpus.
+	Count int
+
+	// Generated is the number of IDs the process generated from the first to
+	// the last ID of the run, inferred from their counter.
+	Generated int
+
+	// Rate is the generation rate of the process, in ids per second.
+	Rate float64
+}
+
+// Duplicate is an ID found more than once.
+type Duplicate struct {
+	ID    xid.ID
+	Count int
+}
+
+// Report analyzes the IDs added so far.
+func (a *Analyzer) Report() *Report {
+	maxRate := a.MaxRate
+	if maxRate <= 0 {
+		maxRate = DefaultMaxRate
+	}
+	ids := append([]xid.ID(nil), a.ids...)
+	sort.Slice(ids, func(i, j int) bool {
+		// Group by machine id and pid, then sort by time and counter
+		if c := compareProcess(ids[i], ids[j]); c != 0 {
+			return c < 0
+		}
+		return ids[i].Compare(ids[j]) < 0
+	})
+	r := &Report{Count: len(ids)}
+	for i := 0; i < len(ids); {
+		j := i + 1
+		for j < len(ids) && compareProcess(ids[i], ids[j]) == 0 {
+			j++
+		}
+		r.Groups = append(r.Groups, analyzeGroup(ids[i:j], maxRate))
+		r.Duplicates = appendDuplicates(r.Duplicates, ids[i:j])
+		i = j
+	}
+	sort.Slice(r.Duplicates, func(i, j int) bool {
+		return r.Duplicates[i].ID.Compare(r.Duplicates[j].ID) < 0
+	})
+	return r
+}
+
+// compareProcess compares the machine id and pid of a and b.
+func compareProcess(a, b xid.ID) int {
+	for k := 4; k < 9; k++ {
+		if a[k] != b[k] {
+			if a[k] < b[k] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// appendDuplicates appends the duplicates of the sorted ids to dups.
+func appendDuplicates(dups []Duplicate, ids []xid.ID) []Duplicate {
+	for i := 0; i < len(ids); {
+		j := i + 1
+		for j < len(ids) && ids[j] == ids[i] {
+			j++
+		}
+		if j-i > 1 {
+			dups = append(dups, Duplicate{ID: ids[i], Count: j - i})
+		}
+		i = j
+	}
+	return dups
+}
+
+// analyzeGroup analyzes the ids of a process, sorted by time and counter.
+func analyzeGroup(ids []xid.ID, maxRate float64) Group {
+	g := Group{
+		Count: len(ids),
+		First: ids[0].Time(),
+		Last:  ids[len(ids)-1].Time(),
+		Pid:   ids[0].Pid(),
+	}
+	copy(g.Machine[:], ids[0].Machine())
+	unwrapSeconds(ids)
+	run := Run{First: g.First, Last: g.First, Count: 1, Generated: 1}
+	for k := 1; k < len(ids); k++ {
+		prev, id := ids[k-1], ids[k]
+		if id == prev {
+			g.Duplicates++
+			run.Count++
+			continue
+		}
+		delta := uint32(id.Counter()-prev.Counter()) & counterMask
+		secs := id.Time().Sub(prev.Time()) / time.Second
+		if float64(delta) > maxRate*float64(secs+1) {
+			g.Runs = append(g.Runs, run.end())
+			run = Run{First: id.Time(), Last: id.Time(), Count: 1, Generated: 1}
+			continue
+		}
+		run.Last = id.Time()
+		run.Count++
+		run.Generated += int(delta)
+	}
+	g.Runs = append(g.Runs, run.end())
+	var generated int
+	var secs float64
+	for _, r := range g.Runs {
+		generated += r.Generated
+		secs += r.seconds()
+	}
+	g.Rate = float64(generated) / secs
+	return g
+}
+
+// seconds returns the duration of the run in seconds. As timestamps have a
+// second precision, the seconds of the first and last ids are included.
+func (r Run) seconds() float64 {
+	return r.Last.Sub(r.First).Seconds() + 1
+}
+
+// end computes the rate of a complete run.
+func (r Run) end() Run {
+	r.Rate = float64(r.Generated) / r.seconds()
+	return r
+}
+
+// unwrapSeconds reorders the ids of each second, sorted by counter, so that
+// an id whose counter wrapped around to 0 comes after the ids generated
+// before the wrap. The sequence of each second is rotated to start after the
+// largest gap between the counters, which is where the sequence started.
+func unwrapSeconds(ids []xid.ID) {
+	for i := 0; i < len(ids); {
+		j := i + 1
+		for j < len(ids) && ids[j].Time().Equal(ids[i].Time()) {
+			j++
+		}
+		start, gap := i, uint32(ids[i].Counter()-ids[j-1].Counter())&counterMask
+		for k := i + 1; k < j; k++ {
+			if g := uint32(ids[k].Counter() - ids[k-1].Counter()); g > gap {
+				start, gap = k, g
+			}
+		}
+		if start != i {
+			rotated := append(append([]xid.ID(nil), ids[start:j]...), ids[i:start]...)
+			copy(ids[i:j], rotated)
+		}
+		i = j
+	}
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+var start = time.Unix(1300816219, 0)
+
+// generate returns every keep-th of the ids generated at rate ids per second
+// for secs seconds from t by a process with the given pid and first counter.
+func generate(t time.Time, pid uint16, counter uint32, rate, secs, keep int) []xid.ID {
+	clock := xid.NewManualClock(t)
+	g := xid.NewGenerator(xid.WithClock(clock), xid.WithMachineID([3]byte{1, 2, 3}), xid.WithPid(pid), xid.WithCounter(counter))
+	var ids []xid.ID
+	for s := 0; s < secs; s++ {
+		for i := 0; i < rate; i++ {
+			if id := g.New(); i%keep == 0 {
+				ids = append(ids, id)
+			}
+		}
+		clock.Advance(time.Second)
+	}
+	return ids
+}
+
+func TestReport(t *testing.T) {
+	var a Analyzer
+	// A process wrapping its counter, restarted with the same pid
+	a.Add(generate(start, 1, 0xffffc0, 100, 3, 10)...)
+	a.Add(generate(start.Add(3*time.Second), 1, 0x500000, 50, 2, 5)...)
+	// Another process, with a duplicated id
+	other := generate(start, 2, 0, 10, 1, 1)
+	a.Add(other...)
+	a.Add(other[3], other[3])
+
+	r := a.Report()
+	if got, want := r.Count, 30+20+10+2; got != want {
+		t.Errorf("Count = %v, want %v", got, want)
+	}
+	if len(r.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(r.Groups))
+	}
+	g := r.Groups[0]
+	if g.Machine != [3]byte{1, 2, 3} || g.Pid != 1 || g.Count != 50 {
+		t.Errorf("group = %v %v %v, want [1 2 3] 1 50", g.Machine, g.Pid, g.Count)
+	}
+	if !g.First.Equal(start) || !g.Last.Equal(start.Add(4*time.Second)) {
+		t.Errorf("group time range = %v-%v", g.First, g.Last)
+	}
+	if got, want := g.Restarts(), 1; got != want {
+		t.Fatalf("Restarts() = %v, want %v", got, want)
+	}
+	runs := []struct {
+		count, generated int
+		rate             float64
+	}{
+		{30, 291, 97},
+		{20, 96, 48},
+	}
+	for k, want := range runs {
+		run := g.Runs[k]
+		if run.Count != want.count || run.Generated != want.generated || run.Rate != want.rate {
+			t.Errorf("run %d = %d ids, %d generated at %v/s, want %d ids, %d generated at %v/s",
+				k, run.Count, run.Generated, run.Rate, want.count, want.generated, want.rate)
+		}
+	}
+	if got, want := g.Rate, float64(291+96)/5; got != want {
+		t.Errorf("Rate = %v, want %v", got, want)
+	}
+	if g := r.Groups[1]; g.Pid != 2 || g.Count != 12 || g.Duplicates != 2 || g.Restarts() != 0 {
+		t.Errorf("group = pid %v, %v ids, %v duplicates, %v restarts, want pid 2, 12 ids, 2 duplicates, 0 restarts",
+			g.Pid, g.Count, g.Duplicates, g.Restarts())
+	}
+	if len(r.Duplicates) != 1 || r.Duplicates[0] != (Duplicate{ID: other[3], Count: 3}) {
+		t.Errorf("Duplicates = %v, want %v x3", r.Duplicates, other[3])
+	}
+}
+
+func TestReportMaxRate(t *testing.T) {
+	ids := generate(start, 1, 0, 100, 2, 100)
+	a := Analyzer{}
+	a.Add(ids...)
+	if got, want := a.Report().Groups[0].Restarts(), 0; got != want {
+		t.Errorf("Restarts() = %v, want %v", got, want)
+	}
+	a = Analyzer{MaxRate: 10}
+	a.Add(ids...)
+	if got, want := a.Report().Groups[0].Restarts(), 1; got != want {
+		t.Errorf("Restarts() = %v, want %v", got, want)
+	}
+}
+
+func TestReportEmpty(t *testing.T) {
+	var a Analyzer
+	if r := a.Report(); r.Count != 0 || len(r.Groups) != 0 || len(r.Duplicates) != 0 {
+		t.Errorf("Report() = %+v, want an empty report", r)
+	}
+}